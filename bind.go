@@ -0,0 +1,507 @@
+// Copyright © 2022 Mark Summerfield. All rights reserved.
+// License: Apache-2.0
+
+package clip
+
+import (
+	"encoding"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Bind creates an option for each exported field in the struct that cfg
+// points to, and arranges for the parsed values to be written back into
+// the struct's fields after a successful parse.
+//
+// Supported field types are bool, string, every int, uint, and float
+// kind, [time.Duration], any type whose pointer implements
+// [encoding.TextUnmarshaler], and slices of any of these (except bool);
+// named types
+// (e.g., type Level int8) are supported by kind. Values must be within
+// their type's limits (and for uints, at most math.MaxInt). Fields of
+// other struct types are bound recursively: embedded structs' options are
+// added as if they were the outer struct's, fields with a cmd tag become
+// subcommands (see [Parser.Subcommand]; use [Parser.Command] to see which
+// was given), and other structs' options are added to an [OptionGroup]. A
+// field's current value is its default (unless a default tag is given);
+// bools must default to false since a flag can only make them true.
+//
+// The struct tags used are:
+//
+//	clip:"name"       // the option's long name; default: field name lowercased
+//	clip:"name,c"     // the long name and short name c
+//	clip:"name,"      // the long name and no short name
+//	clip:"-"          // skip this field
+//	help:"..."        // the option's help text
+//	default:"..."     // the option's default (comma-separated for slices)
+//	range:"min,max"   // the inclusive range for int, uint, and float options
+//	choices:"a,b,c"   // the valid choices for string options
+//	varname:"NAME"    // the option's var name
+//	group:"Title"     // a struct field's group title; default: field name
+//	exclusive:"true"  // a struct field's group is mutually exclusive
+//	cmd:"name"        // a struct field is a subcommand; default: field name
+//
+// For example:
+//
+//	type config struct {
+//		Count   int    `clip:"count,c" help:"How many" default:"2" range:"0,100"`
+//		Format  string `help:"Output format" default:"csv" choices:"csv,json"`
+//		Verbose bool   `help:"Show more output"`
+//	}
+//	parser := NewParser()
+//	var cfg config
+//	if err := Bind(&parser, &cfg); err != nil {
+//		panic(err)
+//	}
+//	parser.ParseLine("-c5 -v") // cfg.Count == 5 && cfg.Verbose
+func Bind(parser *Parser, cfg any) error {
+	value := reflect.ValueOf(cfg)
+	if value.Kind() != reflect.Pointer || value.IsNil() ||
+		value.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("#%d: expected pointer to struct, got %T",
+			eInvalidBinding, cfg)
+	}
+	return bindStruct(parser, value.Elem(), nil)
+}
+
+func bindStruct(parser *Parser, value reflect.Value,
+	group *OptionGroup,
+) error {
+	for i := range value.NumField() {
+		field := value.Type().Field(i)
+		if !field.IsExported() || field.Tag.Get("clip") == "-" {
+			continue
+		}
+		fieldValue := value.Field(i)
+		if field.Type.Kind() == reflect.Struct && !isTextField(fieldValue) {
+			if name, ok := field.Tag.Lookup("cmd"); ok {
+				if name == "" {
+					name = strings.ToLower(field.Name)
+				}
+				subparser := parser.Subcommand(name, field.Tag.Get("help"))
+				if err := bindStruct(subparser, fieldValue,
+					nil); err != nil {
+					return err
+				}
+				continue
+			}
+			subgroup := group
+			if !field.Anonymous {
+				subgroup = bindGroup(parser, field)
+			}
+			if err := bindStruct(parser, fieldValue, subgroup); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := bindField(parser, field, fieldValue, group); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func bindGroup(parser *Parser, field reflect.StructField) *OptionGroup {
+	title := field.Tag.Get("group")
+	if title == "" {
+		title = field.Name
+	}
+	group := parser.Group(title, field.Tag.Get("help"))
+	group.Exclusive = field.Tag.Get("exclusive") == "true"
+	return group
+}
+
+func bindField(parser *Parser, field reflect.StructField,
+	value reflect.Value, group *OptionGroup,
+) error {
+	name, shortName, err := bindNames(field)
+	if err != nil {
+		return err
+	}
+	if text, ok := field.Tag.Lookup("default"); ok {
+		if err := setFromText(value, text); err != nil {
+			return fmt.Errorf("#%d: invalid default for field %s: %w",
+				eInvalidBinding, field.Name, err)
+		}
+	}
+	option, err := bindOption(parser, name, field, value)
+	if err != nil {
+		return err
+	}
+	option.SetShortName(shortName)
+	if group != nil {
+		group.Add(option)
+	}
+	if varName := field.Tag.Get("varname"); varName != "" {
+		if err := option.SetVarName(varName); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func bindNames(field reflect.StructField) (string, rune, error) {
+	name := strings.ToLower(field.Name)
+	tag := field.Tag.Get("clip")
+	left, right, found := strings.Cut(tag, ",")
+	if left != "" {
+		name = left
+	}
+	shortName, _ := namesForName(name)
+	if found {
+		shortName = NoShortName
+		if right != "" {
+			if utf8.RuneCountInString(right) != 1 {
+				return "", NoShortName, fmt.Errorf(
+					"#%d: expected one rune short name for field %s, got %q",
+					eInvalidBinding, field.Name, right)
+			}
+			shortName, _ = utf8.DecodeRuneInString(right)
+		}
+	}
+	return name, shortName, nil
+}
+
+func bindOption(parser *Parser, name string, field reflect.StructField,
+	value reflect.Value,
+) (optioner, error) {
+	help := field.Tag.Get("help")
+	if isTextField(value) {
+		return parser.Text(name, help,
+			value.Addr().Interface().(encoding.TextUnmarshaler)), nil
+	}
+	switch field.Type.Kind() {
+	case reflect.Bool:
+		if value.Bool() {
+			return nil, fmt.Errorf("#%d: bool field %s can't default to "+
+				"true since its flag could never make it false",
+				eInvalidBinding, field.Name)
+		}
+		return parser.FlagVar(fieldPtr[bool](value), name, help), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Int64:
+		if field.Type == durationType {
+			option := Var(parser, name, help, time.Duration(value.Int()),
+				time.ParseDuration)
+			parser.afterParse = append(parser.afterParse, func() {
+				value.SetInt(int64(option.Value()))
+			})
+			return option, nil
+		}
+		return bindInt(parser, name, help, field, value)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64:
+		return bindInt(parser, name, help, field, value)
+	case reflect.Float32, reflect.Float64:
+		return bindReal(parser, name, help, field, value)
+	case reflect.String:
+		option := parser.StrVar(fieldPtr[string](value), name, help,
+			value.String())
+		if choices := field.Tag.Get("choices"); choices != "" {
+			option.choices = strings.Split(choices, ",")
+			option.Validator = makeChoiceValidator(option.choices)
+		}
+		return option, nil
+	case reflect.Slice:
+		if option := bindSlice(parser, name, help, value); option != nil {
+			return option, nil
+		}
+	}
+	return nil, fmt.Errorf("#%d: unsupported type %s for field %s",
+		eInvalidBinding, field.Type, field.Name)
+}
+
+var durationType = reflect.TypeFor[time.Duration]()
+
+// Binds a field of any int or uint kind as an [IntOption] whose values
+// must be within the kind's limits and the field's range (if given).
+func bindInt(parser *Parser, name, help string, field reflect.StructField,
+	value reflect.Value,
+) (optioner, error) {
+	option := parser.Int(name, help, intOf(value))
+	minimum, maximum := intLimits(field.Type)
+	if text := field.Tag.Get("range"); text != "" {
+		low, high, err := bindRange(field, text, strconv.Atoi)
+		if err != nil {
+			return nil, err
+		}
+		if low < minimum || high > maximum {
+			return nil, fmt.Errorf("#%d: range %q is outside type %s's "+
+				"limits for field %s", eInvalidBinding, text, field.Type,
+				field.Name)
+		}
+		minimum, maximum = low, high
+		option.setRange(strconv.Itoa(minimum), strconv.Itoa(maximum))
+	}
+	option.Validator = makeIntRangeValidator(minimum, maximum)
+	parser.afterParse = append(parser.afterParse, func() {
+		setInt(value, option.Value())
+	})
+	return option, nil
+}
+
+// Binds a field of either float kind as a [RealOption] whose values must
+// be within the field's range (if given) and for float32s, float32's
+// limits.
+func bindReal(parser *Parser, name, help string, field reflect.StructField,
+	value reflect.Value,
+) (optioner, error) {
+	option := parser.Real(name, help, value.Float())
+	if text := field.Tag.Get("range"); text != "" {
+		minimum, maximum, err := bindRange(field, text, parseReal)
+		if err != nil {
+			return nil, err
+		}
+		option.Validator = makeRealRangeValidator(minimum, maximum)
+		option.setRange(realText(minimum), realText(maximum))
+	} else if field.Type.Kind() == reflect.Float32 {
+		option.Validator = makeRealRangeValidator(-math.MaxFloat32,
+			math.MaxFloat32)
+	}
+	parser.afterParse = append(parser.afterParse, func() {
+		value.SetFloat(option.Value())
+	})
+	return option, nil
+}
+
+// Returns a multi-value option for the slice field (or nil if its element
+// type isn't supported) which, if given, sets the field after a successful
+// parse.
+func bindSlice(parser *Parser, name, help string,
+	value reflect.Value,
+) optioner {
+	elemType := value.Type().Elem()
+	if reflect.PointerTo(elemType).Implements(textUnmarshalerType) {
+		return bindTextSlice(parser, name, help, value)
+	}
+	switch elemType.Kind() {
+	case reflect.String:
+		option := parser.Strs(name, help)
+		setSliceAfterParse(parser, value, option,
+			func(item reflect.Value, s string) { item.SetString(s) })
+		return option
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16,
+		reflect.Uint32, reflect.Uint64:
+		if elemType == durationType {
+			option := Vars(parser, name, help, time.ParseDuration)
+			setSliceAfterParse(parser, value, option,
+				func(item reflect.Value, d time.Duration) {
+					item.SetInt(int64(d))
+				})
+			return option
+		}
+		option := parser.Ints(name, help)
+		option.Validator = makeIntRangeValidator(intLimits(elemType))
+		setSliceAfterParse(parser, value, option, setInt)
+		return option
+	case reflect.Float32, reflect.Float64:
+		option := parser.Reals(name, help)
+		if elemType.Kind() == reflect.Float32 {
+			option.Validator = makeRealRangeValidator(-math.MaxFloat32,
+				math.MaxFloat32)
+		}
+		setSliceAfterParse(parser, value, option,
+			func(item reflect.Value, r float64) { item.SetFloat(r) })
+		return option
+	}
+	return nil
+}
+
+var textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+
+// Binds a slice field whose element type's pointer implements
+// [encoding.TextUnmarshaler] as a [VarsOption] like those made by [Texts]
+// (which can't be used since its type parameter must be static).
+func bindTextSlice(parser *Parser, name, help string,
+	value reflect.Value,
+) optioner {
+	elemType := value.Type().Elem()
+	defaults := make([]string, 0, value.Len())
+	for i := range value.Len() {
+		if text := marshaledText(value.Index(i).Addr().
+			Interface()); text != "" {
+			defaults = append(defaults, text)
+		}
+	}
+	option := Vars(parser, name, help,
+		func(text string) (reflect.Value, error) {
+			item := reflect.New(elemType)
+			err := item.Interface().(encoding.TextUnmarshaler).
+				UnmarshalText([]byte(text))
+			return item.Elem(), err
+		})
+	option.theDefault = strings.Join(defaults, " ")
+	option.ShowDefault()
+	setSliceAfterParse(parser, value, option,
+		func(item, v reflect.Value) { item.Set(v) })
+	return option
+}
+
+// After a successful parse, if the option was given, sets the slice field
+// to the option's values using set to convert each one; otherwise sets it
+// to a copy of its current values (so that each parse starts afresh).
+func setSliceAfterParse[T any](parser *Parser, value reflect.Value,
+	option interface {
+		Given() bool
+		Value() []T
+	}, set func(reflect.Value, T),
+) {
+	theDefault := cloneSlice(value)
+	parser.afterParse = append(parser.afterParse, func() {
+		if option.Given() {
+			items := option.Value()
+			slice := reflect.MakeSlice(value.Type(), len(items), len(items))
+			for i, item := range items {
+				set(slice.Index(i), item)
+			}
+			value.Set(slice)
+		} else {
+			value.Set(cloneSlice(theDefault))
+		}
+	})
+}
+
+// Returns a copy of the slice value (which is nil if the slice is).
+func cloneSlice(value reflect.Value) reflect.Value {
+	if value.IsNil() {
+		return reflect.Zero(value.Type())
+	}
+	return reflect.AppendSlice(reflect.MakeSlice(value.Type(), 0,
+		value.Len()), value)
+}
+
+// Returns the inclusive limits of the int or uint kind type (with uints
+// limited to math.MaxInt).
+func intLimits(kind reflect.Type) (int, int) {
+	bits := kind.Bits()
+	if isUint(kind.Kind()) {
+		if bits >= strconv.IntSize {
+			return 0, math.MaxInt
+		}
+		return 0, 1<<bits - 1
+	}
+	if bits >= strconv.IntSize {
+		return math.MinInt, math.MaxInt
+	}
+	return -1 << (bits - 1), 1<<(bits-1) - 1
+}
+
+func isUint(kind reflect.Kind) bool {
+	return kind >= reflect.Uint && kind <= reflect.Uint64
+}
+
+// Returns the int or uint kind value as an int (clamped to math.MaxInt).
+func intOf(value reflect.Value) int {
+	if isUint(value.Kind()) {
+		return int(min(value.Uint(), math.MaxInt))
+	}
+	return int(value.Int())
+}
+
+// Sets the int or uint kind value to i (which is within the kind's limits).
+func setInt(value reflect.Value, i int) {
+	if isUint(value.Kind()) {
+		value.SetUint(uint64(i))
+	} else {
+		value.SetInt(int64(i))
+	}
+}
+
+// Returns a *T pointing to the given field; the field's type may be T or
+// any type whose underlying type is T's.
+func fieldPtr[T any](value reflect.Value) *T {
+	return value.Addr().Convert(reflect.TypeFor[*T]()).Interface().(*T)
+}
+
+func bindRange[T int | float64](field reflect.StructField, text string,
+	parse func(string) (T, error),
+) (T, T, error) {
+	left, right, found := strings.Cut(text, ",")
+	minimum, err := parse(strings.TrimSpace(left))
+	if err == nil && found {
+		var maximum T
+		if maximum, err = parse(strings.TrimSpace(right)); err == nil {
+			return minimum, maximum, nil
+		}
+	}
+	return 0, 0, fmt.Errorf("#%d: expected range \"min,max\" for field %s, "+
+		"got %q", eInvalidBinding, field.Name, text)
+}
+
+func isTextField(value reflect.Value) bool {
+	if !value.CanAddr() {
+		return false
+	}
+	_, ok := value.Addr().Interface().(encoding.TextUnmarshaler)
+	return ok
+}
+
+func setFromText(value reflect.Value, text string) error {
+	if isTextField(value) {
+		return value.Addr().Interface().(encoding.TextUnmarshaler).
+			UnmarshalText([]byte(text))
+	}
+	switch value.Kind() {
+	case reflect.Bool:
+		b, err := strconv.ParseBool(text)
+		if err == nil {
+			value.SetBool(b)
+		}
+		return err
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Int64:
+		if value.Type() == durationType {
+			d, err := time.ParseDuration(text)
+			if err == nil {
+				value.SetInt(int64(d))
+			}
+			return err
+		}
+		i, err := strconv.ParseInt(text, 10, value.Type().Bits())
+		if err == nil {
+			value.SetInt(i)
+		}
+		return err
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64:
+		u, err := strconv.ParseUint(text, 10, value.Type().Bits())
+		if err == nil && u > math.MaxInt {
+			err = fmt.Errorf("%d is greater than %d", u, math.MaxInt)
+		}
+		if err == nil {
+			value.SetUint(u)
+		}
+		return err
+	case reflect.Float32, reflect.Float64:
+		r, err := strconv.ParseFloat(text, value.Type().Bits())
+		if err == nil {
+			value.SetFloat(r)
+		}
+		return err
+	case reflect.String:
+		value.SetString(text)
+		return nil
+	case reflect.Slice:
+		parts := strings.Split(text, ",")
+		slice := reflect.MakeSlice(value.Type(), len(parts), len(parts))
+		for i, part := range parts {
+			if err := setFromText(slice.Index(i),
+				strings.TrimSpace(part)); err != nil {
+				return err
+			}
+		}
+		value.Set(slice)
+		return nil
+	}
+	return fmt.Errorf("unsupported type %s", value.Type())
+}
+
+func parseReal(text string) (float64, error) {
+	return strconv.ParseFloat(text, 64)
+}
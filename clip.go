@@ -6,10 +6,9 @@ package clip
 import (
 	_ "embed"
 	"fmt"
+	"io"
 	"os"
 	"strings"
-
-	"github.com/mark-summerfield/uterm"
 )
 
 //go:embed Version.dat
@@ -27,15 +26,73 @@ func appName() string {
 	return "<app>"
 }
 
-func defaultExitFunc(exitCode int, msg string) {
+func defaultExitFunc(exitCode int, msg, hint string) {
 	if exitCode == 0 {
-		fmt.Println(msg)
+		if msg != "" { // "" if already shown, e.g., by a pager
+			fmt.Println(msg)
+		}
 	} else {
-		fmt.Fprintln(os.Stderr, uterm.Red(msg))
-		fmt.Fprintln(os.Stderr, uterm.Red(fmt.Sprintf(
-			"for help run: %s --help", appName())))
+		fmt.Fprintln(os.Stderr, msg)  // already styled
+		fmt.Fprintln(os.Stderr, hint) // ditto
 	}
 	os.Exit(exitCode)
 }
 
 var exitFunc = defaultExitFunc
+
+// ExitError is returned by [Parser.ParseArgs] (and the other parsing
+// methods) if the Parser's Exit function returns rather than ending the
+// program, e.g., after showing the help (Code 0) or an error (Code 2).
+type ExitError struct {
+	Code int
+	Msg  string // The message that was shown (unstyled).
+}
+
+func (me *ExitError) Error() string {
+	return me.Msg
+}
+
+// Shows the msg (on stdout if the code is 0, otherwise as an error on
+// stderr followed by a hint on how to get help) and quits with the given
+// code using the Parser's Stdout, Stderr, and Exit, or if none of them are
+// set, the package's defaults. Returns an *ExitError if the Parser's Exit function returns.
+func (me *Parser) exit(code int, msg string) error {
+	shown, hint := msg, ""
+	if code != 0 {
+		shown = me.styleError(me.Theme.Error, msg)
+		hint = me.styleError(me.Theme.Hint, fmt.Sprintf("for help run: %s --%s",
+			me.appName, me.HelpName))
+	}
+	if me.Stdout == nil && me.Stderr == nil && me.Exit == nil {
+		exitFunc(code, shown, hint)
+	} else {
+		if code == 0 {
+			if msg != "" { // "" if already shown, e.g., by a pager
+				fmt.Fprintln(me.stdout(), msg)
+			}
+		} else {
+			fmt.Fprintln(me.stderr(), shown)
+			fmt.Fprintln(me.stderr(), hint)
+		}
+		if me.Exit != nil {
+			me.Exit(code)
+		} else {
+			os.Exit(code)
+		}
+	}
+	return &ExitError{Code: code, Msg: msg}
+}
+
+func (me *Parser) stdout() io.Writer {
+	if me.Stdout == nil {
+		return os.Stdout
+	}
+	return me.Stdout
+}
+
+func (me *Parser) stderr() io.Writer {
+	if me.Stderr == nil {
+		return os.Stderr
+	}
+	return me.Stderr
+}
@@ -5,14 +5,21 @@ package clip
 
 import (
 	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"math"
+	"math/big"
+	"net/netip"
+	"os"
+	"path/filepath"
 	"reflect"
 	"regexp"
 	"slices"
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 )
 
 func realEqual(x, y float64) bool {
@@ -38,7 +45,7 @@ func expectEmptySlice[T multi](slice []T, what string) string {
 	return ""
 }
 
-func testingExitFunc(exitCode int, msg string) {
+func testingExitFunc(exitCode int, msg, _ string) {
 	panic(fmt.Errorf("exit=%d msg=%q", exitCode, msg))
 }
 
@@ -60,7 +67,7 @@ func expectPanic(code int, t *testing.T) {
 	}
 }
 
-func handleTextExitFunc(_ int, msg string) {
+func handleTextExitFunc(_ int, msg, _ string) {
 	panic(errors.New(msg))
 }
 
@@ -2259,3 +2266,2214 @@ func TestDebSearch2(t *testing.T) {
 		t.Errorf("expected words: %s, got %q", words, parser.Positionals)
 	}
 }
+
+func Test078(t *testing.T) {
+	exitFunc = testingExitFunc
+	parser := NewParser()
+	pagesOpt := parser.IntRangesInRange("pages", "pages help", 1, 20)
+	line := "-p 10-12,1-3,8,2,15-"
+	if err := parser.ParseLine(line); err != nil {
+		t.Error(err)
+	}
+	pages, ranges := pagesOpt.Value()
+	if e := expectEqualSlice([]int{1, 2, 3, 8, 10, 11, 12, 15, 16, 17, 18,
+		19, 20}, pages, "pages"); e != "" {
+		t.Error(e)
+	}
+	expected := []IntRange{{10, 12}, {1, 3}, {8, 8}, {2, 2}, {15, 20}}
+	if !reflect.DeepEqual(ranges, expected) {
+		t.Errorf("expected ranges=%v, got %v", expected, ranges)
+	}
+}
+
+func Test079(t *testing.T) {
+	exitFunc = testingExitFunc
+	parser := NewParser()
+	cpusOpt := parser.IntRanges("cpus", "cpus help")
+	if err := parser.ParseLine(""); err != nil {
+		t.Error(err)
+	}
+	cpus, ranges := cpusOpt.Value()
+	if e := expectEmptySlice(cpus, "cpus"); e != "" {
+		t.Error(e)
+	}
+	if ranges != nil {
+		t.Errorf("expected ranges=nil, got %v", ranges)
+	}
+	parser = NewParser()
+	cpusOpt = parser.IntRanges("cpus", "cpus help")
+	if err := parser.ParseLine("--cpus=-2,4,6-7"); err != nil {
+		t.Error(err)
+	}
+	cpus, _ = cpusOpt.Value()
+	if e := expectEqualSlice([]int{0, 1, 2, 4, 6, 7}, cpus,
+		"cpus"); e != "" {
+		t.Error(e)
+	}
+}
+
+func Test080(t *testing.T) {
+	for _, line := range []string{"-p 0-3", "-p 19-21", "-p 5-3", "-p 1-x",
+		"-p 7,,9"} {
+		func() {
+			exitFunc = testingExitFunc
+			parser := NewParser()
+			parser.IntRangesInRange("pages", "pages help", 1, 20)
+			defer expectPanic(eInvalidValue, t)
+			if err := parser.ParseLine(line); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+}
+
+func Test081(t *testing.T) {
+	exitFunc = testingExitFunc
+	parser := NewParser()
+	parser.IntRanges("cpus", "cpus help")
+	defer expectPanic(eInvalidValue, t)
+	if err := parser.ParseLine("-c 3-"); err != nil {
+		t.Error(err)
+	}
+}
+
+func Test082(t *testing.T) {
+	exitFunc = testingExitFunc
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "in.txt")
+	if err := os.WriteFile(filename, []byte("in"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("CLIP_TEST_DIR", dir)
+	parser := NewParser()
+	infileOpt := parser.Path("infile", "infile help", "")
+	infileOpt.Checks = PathExpand | PathMustBeFile | PathReadable
+	outdirOpt := parser.Path("outdir", "outdir help", ".")
+	outdirOpt.Checks = PathAbsolute | PathMustBeDir | PathWritable
+	parser.PositionalKind = PathExpand | PathParentMustExist | PathWritable
+	line := "-i $CLIP_TEST_DIR/in.txt $CLIP_TEST_DIR/out.txt -"
+	if err := parser.ParseLine(line); err != nil {
+		t.Error(err)
+	}
+	if infileOpt.Value() != filename {
+		t.Errorf("expected infile=%s, got %s", filename, infileOpt.Value())
+	}
+	cwd, _ := os.Getwd()
+	if outdirOpt.Value() != cwd {
+		t.Errorf("expected outdir=%s, got %s", cwd, outdirOpt.Value())
+	}
+	if e := expectEqualSlice([]string{filepath.Join(dir, "out.txt"), "-"},
+		parser.Positionals, "positionals"); e != "" {
+		t.Error(e)
+	}
+}
+
+func Test083(t *testing.T) {
+	dir := t.TempDir()
+	for _, line := range []string{"-i " + dir, "-i " + dir + "/missing",
+		"-o " + dir + "/none/out.txt"} {
+		func() {
+			exitFunc = testingExitFunc
+			parser := NewParser()
+			infileOpt := parser.Path("infile", "infile help", "")
+			infileOpt.Checks = PathMustBeFile
+			outfileOpt := parser.Path("outfile", "outfile help", "")
+			outfileOpt.Checks = PathParentMustExist
+			defer expectPanic(eInvalidValue, t)
+			if err := parser.ParseLine(line); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+}
+
+func Test084(t *testing.T) {
+	exitFunc = testingExitFunc
+	parser := NewParser()
+	parser.PositionalKind = PathMustExist
+	defer expectPanic(eInvalidValue, t)
+	if err := parser.ParseLine(t.TempDir() + "/missing"); err != nil {
+		t.Error(err)
+	}
+}
+
+func Test085(t *testing.T) {
+	exitFunc = testingExitFunc
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "data.txt.gz")
+	parser := NewParser()
+	outfileOpt := parser.OutputFile("outfile", "outfile help", "-")
+	if err := parser.ParseLine("-o " + filename); err != nil {
+		t.Error(err)
+	}
+	writer := outfileOpt.Value()
+	if _, err := writer.Write([]byte("some text")); err != nil {
+		t.Error(err)
+	}
+	if _, err := os.Stat(filename); err == nil {
+		t.Errorf("expected %s to not exist until closed", filename)
+	}
+	if err := writer.Close(); err != nil {
+		t.Error(err)
+	}
+	parser = NewParser()
+	infileOpt := parser.InputFile("infile", "infile help", "-")
+	if err := parser.ParseLine("--infile=" + filename); err != nil {
+		t.Error(err)
+	}
+	reader := infileOpt.Value()
+	defer reader.Close()
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		t.Error(err)
+	}
+	if string(data) != "some text" {
+		t.Errorf("expected \"some text\", got %q", data)
+	}
+}
+
+func Test086(t *testing.T) {
+	exitFunc = testingExitFunc
+	parser := NewParser()
+	infileOpt := parser.InputFile("infile", "infile help", "")
+	outfileOpt := parser.OutputFile("outfile", "outfile help", "")
+	if err := parser.ParseLine("-i - -o - a.txt -"); err != nil {
+		t.Error(err)
+	}
+	if infileOpt.Filename() != "-" {
+		t.Errorf("expected infile=-, got %q", infileOpt.Filename())
+	}
+	if outfileOpt.Filename() != "-" {
+		t.Errorf("expected outfile=-, got %q", outfileOpt.Filename())
+	}
+	if e := expectEqualSlice([]string{"a.txt", "-"}, parser.Positionals,
+		"positionals"); e != "" {
+		t.Error(e)
+	}
+	if parser.PositionalReader(2) != nil {
+		t.Error("expected nil reader for missing positional")
+	}
+}
+
+func Test087(t *testing.T) {
+	exitFunc = testingExitFunc
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "out.txt")
+	parser := NewParser()
+	parser.PositionalCount = OnePositional
+	if err := parser.ParseLine(filename); err != nil {
+		t.Error(err)
+	}
+	writer := parser.PositionalWriter(0)
+	fmt.Fprint(writer, "positional")
+	if err := writer.Close(); err != nil {
+		t.Error(err)
+	}
+	reader := parser.PositionalReader(0)
+	defer reader.Close()
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		t.Error(err)
+	}
+	if string(data) != "positional" {
+		t.Errorf("expected \"positional\", got %q", data)
+	}
+}
+
+func Test088(t *testing.T) {
+	exitFunc = testingExitFunc
+	parser := NewParser()
+	addrOpt := Var(&parser, "addr", "addr help",
+		netip.MustParseAddr("127.0.0.1"), netip.ParseAddr)
+	portsOpt := Vars(&parser, "port", "port help", strconv.Atoi)
+	if err := parser.ParseLine(""); err != nil {
+		t.Error(err)
+	}
+	if addrOpt.Value().String() != "127.0.0.1" {
+		t.Errorf("expected addr=127.0.0.1, got %s", addrOpt.Value())
+	}
+	if e := expectEmptySlice(portsOpt.Value(), "ports"); e != "" {
+		t.Error(e)
+	}
+	parser = NewParser()
+	addrOpt = Var(&parser, "addr", "addr help",
+		netip.MustParseAddr("127.0.0.1"), netip.ParseAddr)
+	portsOpt = Vars(&parser, "port", "port help", strconv.Atoi)
+	if err := parser.ParseLine("-a 192.168.1.7 -p 80 8080"); err != nil {
+		t.Error(err)
+	}
+	if addrOpt.Value().String() != "192.168.1.7" {
+		t.Errorf("expected addr=192.168.1.7, got %s", addrOpt.Value())
+	}
+	if e := expectEqualSlice([]int{80, 8080}, portsOpt.Value(),
+		"ports"); e != "" {
+		t.Error(e)
+	}
+}
+
+func Test089(t *testing.T) {
+	exitFunc = testingExitFunc
+	parser := NewParser()
+	Var(&parser, "addr", "addr help", netip.Addr{}, netip.ParseAddr)
+	defer expectPanic(eInvalidValue, t)
+	if err := parser.ParseLine("-a 192.168.1"); err != nil {
+		t.Error(err)
+	}
+}
+
+func Test090(t *testing.T) {
+	tty = false
+	exitFunc = handleTextExitFunc
+	parser := NewParserUser("myapp", "")
+	addrOpt := Var(&parser, "addr", "addr help", netip.Addr{},
+		netip.ParseAddr)
+	addrOpt.AllowImplicit = true
+	portsOpt := Vars(&parser, "port", "port help", strconv.Atoi)
+	portsOpt.ValueCount = TwoValues
+	parser.PositionalCount = ZeroPositionals
+	expected := `usage: myapp [OPTIONS]
+
+optional arguments:
+  -a, --addr [ADDR]           addr help
+  -p, --port <PORT1> <PORT2>  port help
+  -h, --help                  Show help and quit.
+`
+	defer handleTextAndQuit(expected, t)
+	if err := parser.ParseLine("-h"); err != nil {
+		t.Error(err)
+	}
+}
+
+func Test091(t *testing.T) {
+	exitFunc = testingExitFunc
+	parser := NewParser()
+	addr := netip.MustParseAddr("127.0.0.1")
+	addrOpt := parser.Text("addr", "addr help", &addr)
+	var level big.Int
+	parser.Text("level", "level help", &level)
+	hosts := []netip.Addr{netip.MustParseAddr("10.0.0.1")}
+	hostsOpt := Texts(&parser, "host", "host help", &hosts)
+	line := "-a 192.168.1.7 -l 123456789012345678901234567890 " +
+		"--host 10.0.0.2 10.0.0.3"
+	if err := parser.ParseLine(line); err != nil {
+		t.Error(err)
+	}
+	if addr.String() != "192.168.1.7" || !addrOpt.Given() {
+		t.Errorf("expected addr=192.168.1.7, got %s", addr)
+	}
+	if level.String() != "123456789012345678901234567890" {
+		t.Errorf("expected big level, got %s", level.String())
+	}
+	if len(hosts) != 2 || hosts[0].String() != "10.0.0.2" ||
+		hosts[1].String() != "10.0.0.3" {
+		t.Errorf("expected hosts=[10.0.0.2 10.0.0.3], got %v", hosts)
+	}
+	if len(hostsOpt.Value()) != 2 {
+		t.Errorf("expected 2 hosts, got %v", hostsOpt.Value())
+	}
+}
+
+func Test092(t *testing.T) {
+	tty = false
+	exitFunc = handleTextExitFunc
+	parser := NewParserUser("myapp", "")
+	parser.PositionalCount = ZeroPositionals
+	addr := netip.MustParseAddr("127.0.0.1")
+	parser.Text("addr", "addr help", &addr)
+	hosts := []netip.Addr{netip.MustParseAddr("10.0.0.1"),
+		netip.MustParseAddr("10.0.0.2")}
+	hostsOpt := Texts(&parser, "host", "host help", &hosts)
+	hostsOpt.SetShortName(NoShortName)
+	expected := `usage: myapp [OPTIONS]
+
+optional arguments:
+  -a, --addr ADDR                 addr help [default: 127.0.0.1]
+      --host <HOST1> [HOST2 ...]  host help [default: 10.0.0.1 10.0.0.2]
+  -h, --help                      Show help and quit.
+`
+	defer handleTextAndQuit(expected, t)
+	if err := parser.ParseLine("-h"); err != nil {
+		t.Error(err)
+	}
+}
+
+func Test093(t *testing.T) {
+	exitFunc = testingExitFunc
+	parser := NewParser()
+	var addr netip.Addr
+	parser.Text("addr", "addr help", &addr)
+	defer expectPanic(eInvalidValue, t)
+	if err := parser.ParseLine("-a 1.2.3"); err != nil {
+		t.Error(err)
+	}
+}
+
+type bindTestLimits struct {
+	Width  int     `clip:"maxwidth" help:"max width" default:"80" range:"20,200"`
+	Ratio  float64 `clip:",r" help:"ratio" range:"0,1"`
+	hidden int
+}
+
+type bindTestConfig struct {
+	Count   int        `clip:"count,c" help:"how many" default:"2" range:"0,100"`
+	Format  string     `help:"output format" default:"csv" choices:"csv,json"`
+	Verbose bool       `help:"show more output"`
+	Quiet   bool       `clip:"quiet," help:"show less output"`
+	Include []string   `help:"files to include" varname:"FILE"`
+	Sizes   []int      `clip:"size,z"`
+	Addr    netip.Addr `help:"the address" default:"127.0.0.1"`
+	Skip    string     `clip:"-"`
+	Limits  bindTestLimits
+}
+
+func Test094(t *testing.T) {
+	exitFunc = testingExitFunc
+	parser := NewParser()
+	cfg := bindTestConfig{Skip: "skip", Limits: bindTestLimits{Ratio: 0.5}}
+	if err := Bind(&parser, &cfg); err != nil {
+		t.Fatal(err)
+	}
+	line := "-c5 -v -f json -i a b -z 1 2 3 -m 120"
+	if err := parser.ParseLine(line); err != nil {
+		t.Error(err)
+	}
+	if cfg.Count != 5 || cfg.Format != "json" || !cfg.Verbose ||
+		cfg.Quiet || cfg.Skip != "skip" || cfg.Limits.Width != 120 {
+		t.Errorf("unexpected config %+v", cfg)
+	}
+	if e := expectEqualSlice([]string{"a", "b"}, cfg.Include,
+		"include"); e != "" {
+		t.Error(e)
+	}
+	if e := expectEqualSlice([]int{1, 2, 3}, cfg.Sizes, "sizes"); e != "" {
+		t.Error(e)
+	}
+	if cfg.Addr.String() != "127.0.0.1" || !realEqual(cfg.Limits.Ratio,
+		0.5) {
+		t.Errorf("unexpected defaults %+v", cfg)
+	}
+	if len(parser.groups) != 1 || parser.groups[0].Title != "Limits" ||
+		len(parser.groupOptions(parser.groups[0])) != 2 {
+		t.Error("expected one Limits group with two options")
+	}
+}
+
+func Test095(t *testing.T) {
+	exitFunc = testingExitFunc
+	parser := NewParser()
+	var cfg bindTestConfig
+	if err := Bind(&parser, &cfg); err != nil {
+		t.Fatal(err)
+	}
+	defer expectPanic(eInvalidValue, t)
+	if err := parser.ParseLine("-c 101"); err != nil {
+		t.Error(err)
+	}
+}
+
+func Test096(t *testing.T) {
+	parser := NewParser()
+	if err := Bind(&parser, bindTestConfig{}); err == nil {
+		t.Error("expected error for non-pointer")
+	}
+	var bad struct {
+		Ch chan int
+	}
+	if err := Bind(&parser, &bad); err == nil {
+		t.Error("expected error for unsupported type")
+	}
+	var badRange struct {
+		N int `range:"1"`
+	}
+	if err := Bind(&parser, &badRange); err == nil {
+		t.Error("expected error for bad range")
+	}
+}
+
+func Test097(t *testing.T) {
+	exitFunc = testingExitFunc
+	parser := NewParser()
+	var summary, quiet bool
+	var verbose int
+	var ratio float64
+	var ui, outdir string
+	var langs, excludes []string
+	var sizes []int
+	var scales []float64
+	parser.FlagVar(&summary, "summary", "summary help")
+	parser.FlagVar(&quiet, "quiet", "quiet help")
+	parser.IntVar(&verbose, "verbose", "verbose help", 1)
+	parser.RealVar(&ratio, "ratio", "ratio help", 0.5)
+	parser.StrVar(&ui, "ui", "ui help", "tui")
+	parser.PathVar(&outdir, "outdir", "outdir help", ".")
+	parser.StrsVar(&langs, "language", "language help")
+	excludes = []string{"build"}
+	parser.StrsVar(&excludes, "exclude", "exclude help")
+	parser.IntsVar(&sizes, "z", "sizes help")
+	parser.RealsVar(&scales, "x", "scales help")
+	if verbose != 0 || ui != "" {
+		t.Error("expected variables to be unchanged before parsing")
+	}
+	line := "-s -v3 --ui=gui -l go rs -z 1 2 -x 0.5"
+	if err := parser.ParseLine(line); err != nil {
+		t.Error(err)
+	}
+	if !summary || quiet || verbose != 3 || !realEqual(ratio, 0.5) ||
+		ui != "gui" || outdir != "." {
+		t.Errorf("unexpected values: %t %t %d %g %q %q", summary, quiet,
+			verbose, ratio, ui, outdir)
+	}
+	if e := expectEqualSlice([]string{"go", "rs"}, langs,
+		"langs"); e != "" {
+		t.Error(e)
+	}
+	if e := expectEqualSlice([]string{"build"}, excludes,
+		"excludes"); e != "" {
+		t.Error(e)
+	}
+	if e := expectEqualSlice([]int{1, 2}, sizes, "sizes"); e != "" {
+		t.Error(e)
+	}
+	if e := expectEqualSlice([]float64{0.5}, scales, "scales"); e != "" {
+		t.Error(e)
+	}
+}
+
+func Test098(t *testing.T) {
+	type level int
+	exitFunc = testingExitFunc
+	parser := NewParser()
+	var cfg struct {
+		Level level `default:"3"`
+	}
+	if err := Bind(&parser, &cfg); err != nil {
+		t.Fatal(err)
+	}
+	if err := parser.ParseLine(""); err != nil {
+		t.Error(err)
+	}
+	if cfg.Level != 3 {
+		t.Errorf("expected level=3, got %d", cfg.Level)
+	}
+}
+
+func Test100(t *testing.T) {
+	exitFunc = testingExitFunc
+	fs := flag.NewFlagSet("legacy", flag.ContinueOnError)
+	verbose := fs.Bool("verbose", false, "verbose help")
+	count := fs.Int("c", 2, "count help")
+	name := fs.String("name", "anon", "name help")
+	delay := fs.Duration("delay", time.Second, "delay help")
+	fs.Bool("help", false, "ignored")
+	parser := FromFlagSet(fs)
+	if parser.AppName() != "legacy" {
+		t.Errorf("expected appname=legacy, got %s", parser.AppName())
+	}
+	line := "--verbose -c 5 --delay 2m file.txt"
+	if err := parser.ParseLine(line); err != nil {
+		t.Error(err)
+	}
+	if !*verbose || *count != 5 || *name != "anon" ||
+		*delay != 2*time.Minute {
+		t.Errorf("unexpected values: %t %d %q %s", *verbose, *count, *name,
+			*delay)
+	}
+	if e := expectEqualSlice([]string{"file.txt"}, parser.Positionals,
+		"positionals"); e != "" {
+		t.Error(e)
+	}
+}
+
+func Test101(t *testing.T) {
+	exitFunc = testingExitFunc
+	fs := flag.NewFlagSet("legacy", flag.ContinueOnError)
+	fs.Duration("delay", time.Second, "delay help")
+	parser := FromFlagSet(fs)
+	defer expectPanic(eInvalidValue, t)
+	if err := parser.ParseLine("--delay=soon"); err != nil {
+		t.Error(err)
+	}
+}
+
+func Test102(t *testing.T) {
+	parser := NewParserUser("myapp", "")
+	summaryOpt := parser.Flag("summary", "summary help")
+	verboseOpt := parser.Int("verbose", "verbose help", 1)
+	languageOpt := parser.Strs("language", "language help")
+	fs := parser.ToFlagSet()
+	fs.SetOutput(io.Discard)
+	args := []string{"-s", "--verbose=3", "-l", "go", "-language", "rs",
+		"file.txt"}
+	if err := fs.Parse(args); err != nil {
+		t.Error(err)
+	}
+	if !summaryOpt.Value() || verboseOpt.Value() != 3 {
+		t.Errorf("unexpected values: %t %d", summaryOpt.Value(),
+			verboseOpt.Value())
+	}
+	if e := expectEqualSlice([]string{"go", "rs"}, languageOpt.Value(),
+		"languages"); e != "" {
+		t.Error(e)
+	}
+	if e := expectEqualSlice([]string{"file.txt"}, fs.Args(),
+		"args"); e != "" {
+		t.Error(e)
+	}
+	if err := fs.Parse([]string{"-v", "x"}); err == nil {
+		t.Error("expected invalid int error")
+	}
+}
+
+func createGroupTestParser() Parser {
+	parser := NewParserUser("myapp", "")
+	parser.PositionalCount = ZeroPositionals
+	parser.Flag("verbose", "verbose help")
+	input := parser.Group("Input", "Options for reading.")
+	input.Add(parser.Str("encoding", "encoding help", "utf-8"))
+	output := parser.Group("Output:", "")
+	output.Exclusive = true
+	output.Add(parser.Flag("csv", "csv help"),
+		parser.Flag("json", "json help"))
+	input.Add(parser.Int("indent-width", "indent help", 2))
+	return parser
+}
+
+func Test103(t *testing.T) {
+	tty = false
+	exitFunc = handleTextExitFunc
+	parser := createGroupTestParser()
+	parser.AlignGroupsSeparately = true
+	expected := `usage: myapp [OPTIONS]
+
+optional arguments:
+  -v, --verbose  verbose help
+  -h, --help     Show help and quit.
+
+Input:
+  Options for reading.
+  -e, --encoding ENCODING          encoding help
+  -i, --indent-width INDENT_WIDTH  indent help
+
+Output:
+  -c, --csv   csv help
+  -j, --json  json help
+`
+	defer handleTextAndQuit(expected, t)
+	if err := parser.ParseLine("-h"); err != nil {
+		t.Error(err)
+	}
+}
+
+func Test104(t *testing.T) {
+	tty = false
+	exitFunc = handleTextExitFunc
+	parser := createGroupTestParser()
+	expected := `usage: myapp [OPTIONS]
+
+optional arguments:
+  -v, --verbose                    verbose help
+  -h, --help                       Show help and quit.
+
+Input:
+  Options for reading.
+  -e, --encoding ENCODING          encoding help
+  -i, --indent-width INDENT_WIDTH  indent help
+
+Output:
+  -c, --csv                        csv help
+  -j, --json                       json help
+`
+	defer handleTextAndQuit(expected, t)
+	if err := parser.ParseLine("-h"); err != nil {
+		t.Error(err)
+	}
+}
+
+func Test105(t *testing.T) {
+	exitFunc = testingExitFunc
+	parser := createGroupTestParser()
+	if err := parser.ParseLine("-v -j -i4"); err != nil {
+		t.Error(err)
+	}
+	parser = createGroupTestParser()
+	defer expectPanic(eMutuallyExclusive, t)
+	if err := parser.ParseLine("-c -j"); err != nil {
+		t.Error(err)
+	}
+}
+
+func Test106(t *testing.T) {
+	tty = false
+	exitFunc = handleTextExitFunc
+	parser := NewParserUser("myapp", "")
+	parser.PositionalCount = ZeroPositionals
+	parser.ShowDefaults = true
+	parser.IntInRange("indent", "indent help", 0, 8, 2)
+	parser.RealInRange("ratio", "ratio help", -1, 1, 0.5)
+	parser.Choice("format", "format help", []string{"csv", "json"}, "csv")
+	langOpt := parser.Str("language", "language help", "")
+	langOpt.SetDefaultText("all known")
+	nameOpt := parser.Str("name", "name help", "anon")
+	nameOpt.HideDefault()
+	parser.Flag("quiet", "quiet help")
+	expected := `usage: myapp [OPTIONS]
+
+optional arguments:
+  -i, --indent INDENT      indent help [range: 0..8] [default: 2]
+  -r, --ratio RATIO        ratio help [range: -1..1] [default: 0.5]
+  -f, --format FORMAT      format help [choices: csv json] [default: csv]
+  -l, --language LANGUAGE  language help [default: all known]
+  -n, --name NAME          name help
+  -q, --quiet              quiet help
+  -h, --help               Show help and quit.
+`
+	defer handleTextAndQuit(expected, t)
+	if err := parser.ParseLine("-h"); err != nil {
+		t.Error(err)
+	}
+}
+
+func Test107(t *testing.T) {
+	tty = false
+	exitFunc = handleTextExitFunc
+	parser := NewParserUser("myapp", "")
+	parser.PositionalCount = ZeroPositionals
+	parser.DefaultFormat = "(default %s)"
+	parser.IntInRange("indent", "indent help", 0, 8, 2)
+	widthOpt := parser.Int("width", "width help", 80)
+	widthOpt.ShowDefault()
+	expected := `usage: myapp [OPTIONS]
+
+optional arguments:
+  -i, --indent INDENT  indent help
+  -w, --width WIDTH    width help (default 80)
+  -h, --help           Show help and quit.
+`
+	defer handleTextAndQuit(expected, t)
+	if err := parser.ParseLine("-h"); err != nil {
+		t.Error(err)
+	}
+}
+
+func Test108(t *testing.T) {
+	tty = false
+	exitFunc = handleTextExitFunc
+	parser := NewParserUser("myapp", "")
+	parser.PositionalCount = ZeroOrMorePositionals
+	parser.Int("indent", "indent help", 2)
+	parser.Flag("quiet", "quiet help").Hide()
+	parser.HelpTemplate = `{{strong .AppName}} {{.Usage}}
+{{range .Options}}{{.LongName}}{{.ArgText}} ({{.ShortName}}) {{.Help}}
+{{end}}`
+	expected := `myapp [OPTIONS] [FILE1 [FILE2 ...]]
+--indent INDENT (-i) indent help
+--help (-h) Show help and quit.
+`
+	defer handleTextAndQuit(expected, t)
+	if err := parser.ParseLine("-h"); err != nil {
+		t.Error(err)
+	}
+}
+
+func Test109(t *testing.T) {
+	parser := createGroupTestParser()
+	model := parser.HelpModel()
+	if model.AppName != "myapp" {
+		t.Errorf("expected myapp, got %q", model.AppName)
+	}
+	if len(model.Groups) == 0 || !strings.HasSuffix(model.Groups[0].Title,
+		":") {
+		t.Errorf("expected groups with colon titles, got %v", model.Groups)
+	}
+	last := model.Options[len(model.Options)-1]
+	if last.LongName != "--help" || last.ShortName != "-h" {
+		t.Errorf("expected --help last, got %v", last)
+	}
+	exitFunc = testingExitFunc
+	defer expectPanic(eInvalidHelpTemplate, t)
+	parser.HelpTemplate = "{{.NoSuchField}}"
+	parser.OnHelp()
+}
+
+func newExampleTestParser() Parser {
+	parser := NewParserUser("myapp", "")
+	parser.PositionalCount = OneOrMorePositionals
+	parser.Flag("verbose", "verbose help")
+	parser.Choice("format", "format help", []string{"csv", "json"}, "csv")
+	parser.Example("-v --format=json data.txt", "Convert data.txt to JSON.")
+	parser.Example("in1.txt in2.txt", "")
+	return parser
+}
+
+func Test110(t *testing.T) {
+	tty = false
+	exitFunc = handleTextExitFunc
+	parser := newExampleTestParser()
+	parser.EndDesc = "See the manual."
+	expected := `usage: myapp [OPTIONS] <FILE1> [FILE2 [FILE3 ...]]
+
+
+positional arguments:
+  <FILE1> [FILE2 [FILE3 ...]]
+
+optional arguments:
+  -v, --verbose        verbose help
+  -f, --format FORMAT  format help
+  -h, --help           Show help and quit.
+
+examples:
+  myapp -v --format=json data.txt
+        Convert data.txt to JSON.
+  myapp in1.txt in2.txt
+
+See the manual.
+`
+	defer handleTextAndQuit(expected, t)
+	if err := parser.ParseLine("-h"); err != nil {
+		t.Error(err)
+	}
+}
+
+func Test111(t *testing.T) {
+	if err := CheckExamples(newExampleTestParser); err != nil {
+		t.Error(err)
+	}
+	err := CheckExamples(func() Parser {
+		parser := newExampleTestParser()
+		parser.Example("--format=xml data.txt", "Convert to XML.")
+		return parser
+	})
+	if err == nil || !strings.Contains(err.Error(), "--format=xml") {
+		t.Errorf("expected invalid example error, got %v", err)
+	}
+}
+
+func newBriefHelpTestParser() Parser {
+	parser := NewParserUser("myapp", "")
+	parser.PositionalCount = ZeroPositionals
+	parser.LongDesc = "Converts data."
+	parser.BriefHelp = true
+	parser.Flag("verbose", "verbose help")
+	parser.Int("buffer-size", "buffer help", 4096).Advanced()
+	parser.Flag("debug", "debug help").Hide()
+	parser.Example("-v", "Convert verbosely.")
+	return parser
+}
+
+func Test112(t *testing.T) {
+	tty = false
+	exitFunc = handleTextExitFunc
+	parser := newBriefHelpTestParser()
+	expected := `usage: myapp [OPTIONS]
+
+optional arguments:
+  -v, --verbose  verbose help
+  -h, --help     Show help and quit.
+
+for more help run: myapp --help
+`
+	defer handleTextAndQuit(expected, t)
+	if err := parser.ParseLine("-h"); err != nil {
+		t.Error(err)
+	}
+}
+
+func Test113(t *testing.T) {
+	tty = false
+	exitFunc = handleTextExitFunc
+	parser := newBriefHelpTestParser()
+	expected := `usage: myapp [OPTIONS]
+
+Converts data.
+
+optional arguments:
+  -v, --verbose                  verbose help
+  -b, --buffer-size BUFFER_SIZE  buffer help
+  -h, --help                     Show help and quit.
+
+examples:
+  myapp -v
+        Convert verbosely.
+`
+	defer handleTextAndQuit(expected, t)
+	if err := parser.ParseLine("--help"); err != nil {
+		t.Error(err)
+	}
+}
+
+func Test114(t *testing.T) {
+	tty = false
+	exitFunc = handleTextExitFunc
+	parser := newBriefHelpTestParser()
+	parser.BriefHelp = false
+	expected := `usage: myapp [OPTIONS]
+
+Converts data.
+
+optional arguments:
+  -v, --verbose                  verbose help
+  -b, --buffer-size BUFFER_SIZE  buffer help
+  -d, --debug                    debug help
+  -h, --help                     Show help and quit.
+
+examples:
+  myapp -v
+        Convert verbosely.
+`
+	defer handleTextAndQuit(expected, t)
+	if err := parser.ParseLine("--help-all"); err != nil {
+		t.Error(err)
+	}
+}
+
+func newTopicTestParser() Parser {
+	parser := newExampleTestParser()
+	formatOpt := parser.optionForTopic("format")
+	formatOpt.SetLongHelp("The output format: csv for comma-separated " +
+		"values or json for JSON.")
+	parser.HelpTopic("formats", "Both formats are UTF-8 encoded.")
+	return parser
+}
+
+func Test115(t *testing.T) {
+	tty = false
+	exitFunc = handleTextExitFunc
+	parser := newTopicTestParser()
+	expected := `-f, --format FORMAT
+
+The output format: csv for comma-separated values or json for JSON.
+
+choices: csv json
+default: csv
+
+examples:
+  myapp -v --format=json data.txt
+        Convert data.txt to JSON.
+`
+	defer handleTextAndQuit(expected, t)
+	if err := parser.ParseLine("--help=format"); err != nil {
+		t.Error(err)
+	}
+}
+
+func Test116(t *testing.T) {
+	tty = false
+	exitFunc = handleTextExitFunc
+	parser := newTopicTestParser()
+	expected := `help topics:
+  formats
+
+for help on an option or topic run: myapp --help=NAME
+`
+	defer handleTextAndQuit(expected, t)
+	if err := parser.ParseLine("--help=topics"); err != nil {
+		t.Error(err)
+	}
+}
+
+func Test117(t *testing.T) {
+	tty = false
+	exitFunc = handleTextExitFunc
+	parser := newTopicTestParser()
+	expected := "Both formats are UTF-8 encoded."
+	defer handleTextAndQuit(expected, t)
+	parser.OnTopicHelp("formats")
+}
+
+func Test118(t *testing.T) {
+	exitFunc = testingExitFunc
+	parser := newTopicTestParser()
+	defer expectPanic(eUnknownHelpTopic, t)
+	if err := parser.ParseLine("--help=colors"); err != nil {
+		t.Error(err)
+	}
+}
+
+func Test119(t *testing.T) {
+	t.Setenv("TERM", "xterm")
+	t.Setenv("PAGER", "more -s")
+	if command := pagerCommand(); !slices.Equal(command,
+		[]string{"more", "-s"}) {
+		t.Errorf("expected [more -s], got %v", command)
+	}
+	os.Unsetenv("PAGER")
+	if command := pagerCommand(); !slices.Equal(command,
+		[]string{"less", "-R"}) {
+		t.Errorf("expected [less -R], got %v", command)
+	}
+	t.Setenv("PAGER", "")
+	if command := pagerCommand(); command != nil {
+		t.Errorf("expected no pager, got %v", command)
+	}
+	t.Setenv("PAGER", "less")
+	t.Setenv("TERM", "dumb")
+	if command := pagerCommand(); command != nil {
+		t.Errorf("expected no pager, got %v", command)
+	}
+	t.Setenv("TERM", "xterm")
+	t.Setenv("NO_PAGER", "1")
+	if command := pagerCommand(); command != nil {
+		t.Errorf("expected no pager, got %v", command)
+	}
+	stdoutTerminal = false
+	parser := NewParserUser("myapp", "")
+	parser.UsePager = true
+	if parser.page("text") {
+		t.Error("expected no paging when stdout isn't a terminal")
+	}
+}
+
+func Test120(t *testing.T) {
+	t.Setenv("FORCE_COLOR", "")
+	t.Setenv("TERM", "xterm")
+	t.Setenv("NO_COLOR", "")
+	if !colorWanted(true) || colorWanted(false) {
+		t.Error("expected color only for a terminal")
+	}
+	t.Setenv("NO_COLOR", "1")
+	if colorWanted(true) {
+		t.Error("expected no color for NO_COLOR")
+	}
+	t.Setenv("NO_COLOR", "")
+	t.Setenv("FORCE_COLOR", "1")
+	if !colorWanted(false) {
+		t.Error("expected color for FORCE_COLOR")
+	}
+	t.Setenv("FORCE_COLOR", "0")
+	t.Setenv("TERM", "dumb")
+	if colorWanted(true) {
+		t.Error("expected no color for TERM=dumb")
+	}
+}
+
+func Test121(t *testing.T) {
+	tty = false
+	exitFunc = handleTextExitFunc
+	parser := NewParserUser("myapp", "")
+	parser.PositionalCount = ZeroPositionals
+	parser.Color = ColorAlways
+	parser.Theme = Theme{Heading: "<H>", OptionName: "<O>", VarName: "<V>"}
+	parser.Int("indent", "indent help", 2)
+	expected := "usage: <O>myapp\x1b[0m [OPTIONS]\n\n" +
+		"<H>optional arguments:\x1b[0m\n" +
+		"  <O>-i\x1b[0m, <O>--indent\x1b[0m <V>INDENT\x1b[0m  indent help\n" +
+		"  <O>-h\x1b[0m, <O>--help\x1b[0m           Show help and quit."
+	expected = strings.Replace(expected, "usage:", "<H>usage:\x1b[0m", 1)
+	defer handleTextAndQuit(expected, t)
+	if err := parser.ParseLine("-h"); err != nil {
+		t.Error(err)
+	}
+}
+
+func Test122(t *testing.T) {
+	tty = true
+	exitFunc = handleTextExitFunc
+	parser := NewParserUser("myapp", "")
+	parser.PositionalCount = ZeroPositionals
+	parser.ColorName = "color"
+	expected := `usage: myapp [OPTIONS]
+
+optional arguments:
+      --color COLOR  When to use color: auto, always, or never.
+  -h, --help         Show help and quit.
+`
+	defer func() { tty = false }()
+	defer handleTextAndQuit(expected, t)
+	if err := parser.ParseLine("-h --color=never"); err != nil {
+		t.Error(err)
+	}
+}
+
+func newStreamsTestParser(stdout, stderr io.Writer, code *int) Parser {
+	parser := NewParserUser("myapp", "1.0.0")
+	parser.PositionalCount = ZeroPositionals
+	parser.Stdout = stdout
+	parser.Stderr = stderr
+	parser.Exit = func(exitCode int) { *code = exitCode }
+	parser.Flag("quiet", "quiet help")
+	return parser
+}
+
+func Test123(t *testing.T) {
+	t.Parallel()
+	var stdout, stderr strings.Builder
+	code := -1
+	parser := newStreamsTestParser(&stdout, &stderr, &code)
+	err := parser.ParseLine("--version")
+	var exitErr *ExitError
+	if !errors.As(err, &exitErr) || exitErr.Code != 0 || code != 0 {
+		t.Errorf("expected exit code 0, got %v %d", err, code)
+	}
+	if stdout.String() != "myapp v1.0.0\n" || stderr.String() != "" {
+		t.Errorf("unexpected output %q %q", stdout.String(),
+			stderr.String())
+	}
+}
+
+func Test124(t *testing.T) {
+	t.Parallel()
+	var stdout, stderr strings.Builder
+	code := -1
+	parser := newStreamsTestParser(&stdout, &stderr, &code)
+	err := parser.ParseLine("--loud")
+	var exitErr *ExitError
+	if !errors.As(err, &exitErr) || exitErr.Code != 2 || code != 2 {
+		t.Errorf("expected exit code 2, got %v %d", err, code)
+	}
+	expected := "error #106: unrecognized option --loud\n" +
+		"for help run: myapp --help\n"
+	if stdout.String() != "" || stderr.String() != expected {
+		t.Errorf("unexpected output %q %q", stdout.String(),
+			stderr.String())
+	}
+	stdout.Reset()
+	parser = newStreamsTestParser(&stdout, &stderr, &code)
+	err = parser.ParseLine("-h")
+	if !errors.As(err, &exitErr) || exitErr.Code != 0 || code != 0 {
+		t.Errorf("expected exit code 0, got %v %d", err, code)
+	}
+	if !strings.HasPrefix(stdout.String(), "usage: myapp [OPTIONS]\n") {
+		t.Errorf("expected help, got %q", stdout.String())
+	}
+}
+
+func Test125(t *testing.T) {
+	parser := NewParserUser("myapp", "1.0.0")
+	parser.ColorName = "color"
+	verboseOpt := parser.Flag("verbose", "verbose help")
+	namesOpt := parser.Strs("names", "names help")
+	countOpt := parser.Int("count", "count help", 3)
+	if err := parser.ParseLine("-v --names a b -c 5 -- x.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if !verboseOpt.Value() || len(namesOpt.Value()) != 2 ||
+		countOpt.Value() != 5 || len(parser.Positionals) != 1 {
+		t.Errorf("unexpected first parse results")
+	}
+	optionCount := len(parser.options)
+	if err := parser.ParseLine("--names c -- y.txt z.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if verboseOpt.Value() || verboseOpt.Given() {
+		t.Error("expected verbose to be reset")
+	}
+	if e := expectEqualSlice([]string{"c"}, namesOpt.Value(),
+		"names"); e != "" {
+		t.Error(e)
+	}
+	if countOpt.Value() != 3 || countOpt.Given() {
+		t.Errorf("expected count=3, got %d", countOpt.Value())
+	}
+	if e := expectEqualSlice([]string{"y.txt", "z.txt"}, parser.Positionals,
+		"positionals"); e != "" {
+		t.Error(e)
+	}
+	if len(parser.options) != optionCount {
+		t.Errorf("expected %d options, got %d", optionCount,
+			len(parser.options))
+	}
+	parser.Reset()
+	if namesOpt.Value() != nil || parser.Positionals != nil {
+		t.Error("expected Reset to clear the values and positionals")
+	}
+}
+
+func newTestShell(input string) (*Shell, *strings.Builder, *strings.Builder,
+	*[]string,
+) {
+	var stdout, stderr strings.Builder
+	var added []string
+	shell := NewShell()
+	shell.Prompt = ""
+	shell.In = strings.NewReader(input)
+	shell.Out = &stdout
+	shell.Err = &stderr
+	add := shell.Command("add", "Add items.", func(parser *Parser) error {
+		added = append(added, parser.Positionals...)
+		return nil
+	})
+	add.PositionalCount = OneOrMorePositionals
+	add.Choice("format", "format help", []string{"csv", "json"}, "csv")
+	shell.Command("fail", "Always fails.", func(*Parser) error {
+		return errors.New("failed")
+	})
+	return &shell, &stdout, &stderr, &added
+}
+
+func Test126(t *testing.T) {
+	shell, stdout, stderr, added := newTestShell(
+		"add a 'b c'\n\nadd\nfail\nnope\nhistory\nexit\nadd d\n")
+	if err := shell.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if e := expectEqualSlice([]string{"a", "b c"}, *added,
+		"added"); e != "" {
+		t.Error(e)
+	}
+	expected := "error #108: expected one or more positional arguments, " +
+		"got 0\nfor help run: add --help\nfailed\n" +
+		"unknown command nope (for help run: help)\n"
+	if stderr.String() != expected {
+		t.Errorf("expected stderr %q, got %q", expected, stderr.String())
+	}
+	expected = "  1  add a 'b c'\n  2  add\n  3  fail\n  4  nope\n" +
+		"  5  history\n"
+	if stdout.String() != expected {
+		t.Errorf("expected stdout %q, got %q", expected, stdout.String())
+	}
+}
+
+func Test127(t *testing.T) {
+	shell, stdout, _, _ := newTestShell("help\nhelp add\n")
+	if err := shell.Run(); err != nil {
+		t.Fatal(err)
+	}
+	expected := `commands:
+  add      Add items.
+  fail     Always fails.
+  help     Show the commands or a command's help (help NAME).
+  history  Show the lines given so far.
+  exit     Quit (or use quit).
+usage: add [OPTIONS] <FILE1> [FILE2 [FILE3 ...]]
+`
+	if !strings.HasPrefix(stdout.String(), expected) {
+		t.Errorf("expected stdout %q, got %q", expected, stdout.String())
+	}
+}
+
+func Test128(t *testing.T) {
+	shell, _, _, _ := newTestShell("")
+	for _, tc := range []struct {
+		line     string
+		expected []string
+	}{
+		{"", []string{"add", "exit", "fail", "help", "history", "quit"}},
+		{"h", []string{"help", "history"}},
+		{"help a", []string{"add"}},
+		{"add --f", []string{"--format"}},
+		{"add -", []string{"--format", "--help"}},
+		{"add --format ", []string{"csv", "json"}},
+		{"add --format=j", []string{"--format=json"}},
+		{"add x", nil},
+	} {
+		actual := shell.Complete(tc.line)
+		if len(actual) != len(tc.expected) || (len(actual) > 0 &&
+			!slices.Equal(actual, tc.expected)) {
+			t.Errorf("Complete(%q): expected %v, got %v", tc.line,
+				tc.expected, actual)
+		}
+	}
+	words, err := splitLine(`add "a b" c\ d 'e\f'`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e := expectEqualSlice([]string{"add", "a b", "c d", `e\f`}, words,
+		"words"); e != "" {
+		t.Error(e)
+	}
+	if _, err := splitLine(`add "a`); err == nil {
+		t.Error("expected unterminated quote error")
+	}
+}
+
+func newNamedPositionalsTestParser() (Parser, *StrPositional,
+	*IntPositional, *RestPositional,
+) {
+	parser := NewParserUser("myapp", "")
+	parser.Flag("verbose", "verbose help")
+	src := parser.Positional("SRC", "The source.")
+	count := parser.PositionalInt("COUNT", "How many copies.", 1)
+	count.MakeOptional()
+	patterns := parser.PositionalRest("PATTERN", "Patterns to match.")
+	return parser, src, count, patterns
+}
+
+func Test129(t *testing.T) {
+	parser, src, count, patterns := newNamedPositionalsTestParser()
+	if err := parser.ParseLine("-v in.txt 3 *.go *.md"); err != nil {
+		t.Fatal(err)
+	}
+	if src.Value() != "in.txt" || !src.Given() {
+		t.Errorf("expected SRC in.txt, got %q", src.Value())
+	}
+	if count.Value() != 3 {
+		t.Errorf("expected COUNT 3, got %d", count.Value())
+	}
+	if e := expectEqualSlice([]string{"*.go", "*.md"}, patterns.Value(),
+		"PATTERN"); e != "" {
+		t.Error(e)
+	}
+	if len(parser.Positionals) != 4 {
+		t.Errorf("expected 4 positionals, got %d", len(parser.Positionals))
+	}
+	if err := parser.ParseLine("out.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if src.Value() != "out.txt" || count.Value() != 1 || count.Given() ||
+		patterns.Value() != nil {
+		t.Errorf("expected out.txt 1 [], got %q %d %v", src.Value(),
+			count.Value(), patterns.Value())
+	}
+}
+
+func Test130(t *testing.T) {
+	tty = false
+	exitFunc = handleTextExitFunc
+	parser, _, _, _ := newNamedPositionalsTestParser()
+	expected := `usage: myapp [OPTIONS] <SRC> [COUNT] [PATTERN ...]
+
+
+positional arguments:
+  SRC      The source.
+  COUNT    How many copies.
+  PATTERN  Patterns to match.
+
+optional arguments:
+  -v, --verbose  verbose help
+  -h, --help     Show help and quit.`
+	defer handleTextAndQuit(expected, t)
+	if err := parser.ParseLine("-h"); err != nil {
+		t.Error(err)
+	}
+}
+
+func Test131(t *testing.T) {
+	parser, _, _, _ := newNamedPositionalsTestParser()
+	exitFunc = testingExitFunc
+	defer expectPanic(eWrongPositionalCount, t)
+	_ = parser.ParseLine("-v")
+}
+
+func Test132(t *testing.T) {
+	parser, _, _, _ := newNamedPositionalsTestParser()
+	exitFunc = testingExitFunc
+	defer expectPanic(eInvalidValue, t)
+	_ = parser.ParseLine("in.txt three")
+}
+
+func Test133(t *testing.T) {
+	parser := NewParserUser("myapp", "")
+	parser.Positional("SRC", "The source.")
+	parser.Positional("DST", "The destination.")
+	exitFunc = testingExitFunc
+	defer expectPanic(eWrongPositionalCount, t)
+	_ = parser.ParseLine("a b c")
+}
+
+func Test134(t *testing.T) {
+	parser := NewParserUser("myapp", "")
+	parser.PositionalRest("PATTERN", "Patterns to match.")
+	parser.Positional("DST", "The destination.")
+	exitFunc = testingExitFunc
+	defer expectPanic(eInvalidPositional, t)
+	_ = parser.ParseLine("a b")
+}
+
+func Test135(t *testing.T) {
+	expected := map[PositionalCount]string{
+		ZeroPositionals:       "",
+		ZeroOrOnePositionals:  "[FILE1]",
+		ZeroToTwoPositionals:  "[FILE1 [FILE2]]",
+		ZeroOrMorePositionals: "[FILE1 [FILE2 ...]]",
+		OnePositional:         "<FILE1>",
+		TwoPositionals:        "<FILE1> <FILE2>",
+		TwoOrThreePositionals: "<FILE1> <FILE2> [FILE3]",
+		ThreePositionals:      "<FILE1> <FILE2> <FILE3>",
+		FourPositionals:       "<FILE1> <FILE2> <FILE3> <FILE4>",
+		OneOrTwoPositionals:   "<FILE1> [FILE2]",
+		OneToThreePositionals: "<FILE1> [FILE2 [FILE3]]",
+		OneOrMorePositionals:  "<FILE1> [FILE2 [FILE3 ...]]",
+	}
+	for count, text := range expected {
+		minimum, maximum := count.bounds()
+		actual := positionalRangeText(minimum, maximum, "FILE", "")
+		if actual != text {
+			t.Errorf("%s: expected %q, got %q", count, text, actual)
+		}
+	}
+	actual := positionalRangeText(1, Unlimited, "SRC", "DST")
+	if actual != "<SRC> [DST1 [DST2 ...]]" {
+		t.Errorf("expected <SRC> [DST1 [DST2 ...]], got %q", actual)
+	}
+}
+
+func Test136(t *testing.T) {
+	parser := NewParserUser("myapp", "")
+	parser.PositionalCount = OnePositional // overridden
+	parser.MustSetPositionalRange(2, 4)
+	if usage := parser.HelpModel().Usage; usage !=
+		"[OPTIONS] <FILE1> <FILE2> [FILE3 [FILE4]]" {
+		t.Errorf("unexpected usage %q", usage)
+	}
+	for _, line := range []string{"a b", "a b c", "a b c d"} {
+		if err := parser.ParseLine(line); err != nil {
+			t.Errorf("%s: %s", line, err)
+		}
+	}
+	exitFunc = handleTextExitFunc
+	defer handleTextAndQuit(
+		"error #108: expected 2 to 4 positional arguments, got 5", t)
+	_ = parser.ParseLine("a b c d e")
+}
+
+func Test137(t *testing.T) {
+	parser := NewParserUser("myapp", "")
+	parser.MustSetPositionalRange(3, Unlimited)
+	if usage := parser.HelpModel().Usage; usage !=
+		"[OPTIONS] <FILE1> <FILE2> <FILE3> [FILE4 [FILE5 ...]]" {
+		t.Errorf("unexpected usage %q", usage)
+	}
+	if err := parser.ParseLine("a b c d e f g"); err != nil {
+		t.Error(err)
+	}
+	exitFunc = handleTextExitFunc
+	defer handleTextAndQuit(
+		"error #108: expected at least 3 positional arguments, got 2", t)
+	_ = parser.ParseLine("a b")
+}
+
+func Test138(t *testing.T) {
+	parser := NewParserUser("myapp", "")
+	for _, bounds := range [][2]int{{-1, 2}, {3, 2}, {2, Unlimited - 1}} {
+		if err := parser.SetPositionalRange(bounds[0], bounds[1]); err == nil {
+			t.Errorf("expected an error for %v", bounds)
+		}
+	}
+	if err := parser.SetPositionalRange(0, 0); err != nil {
+		t.Error(err)
+	}
+	if usage := parser.HelpModel().Usage; usage != "[OPTIONS]" {
+		t.Errorf("unexpected usage %q", usage)
+	}
+}
+
+func Test139(t *testing.T) {
+	parser := NewParserUser("myapp", "")
+	includeOpt := parser.Strs("include", "include help")
+	includeOpt.MinValues = 2
+	includeOpt.MaxValues = 5
+	if argText := optArgText(includeOpt); argText !=
+		" <INCLUDE1> <INCLUDE2> [INCLUDE3 [INCLUDE4 [INCLUDE5]]]" {
+		t.Errorf("unexpected arg text %q", argText)
+	}
+	if err := parser.ParseLine("-i a b c"); err != nil {
+		t.Fatal(err)
+	}
+	if e := expectEqualSlice([]string{"a", "b", "c"}, includeOpt.Value(),
+		"include"); e != "" {
+		t.Error(e)
+	}
+	exitFunc = handleTextExitFunc
+	defer handleTextAndQuit(
+		"error #102: expected 2 to 5 values for include, got 6", t)
+	_ = parser.ParseLine("-i a b c d e f")
+}
+
+func Test140(t *testing.T) {
+	parser := NewParserUser("myapp", "")
+	numbersOpt := parser.Ints("numbers", "numbers help")
+	numbersOpt.ValueCount = ZeroOrMoreValues
+	if argText := optArgText(numbersOpt); argText != " [NUMBERS1 ...]" {
+		t.Errorf("unexpected arg text %q", argText)
+	}
+	if err := parser.ParseLine("-n"); err != nil {
+		t.Fatal(err)
+	}
+	if !numbersOpt.Given() || numbersOpt.Value() != nil {
+		t.Errorf("expected given with no values, got %v",
+			numbersOpt.Value())
+	}
+	if err := parser.ParseLine("-n 1 2 3 4 5 6 7"); err != nil {
+		t.Fatal(err)
+	}
+	if len(numbersOpt.Value()) != 7 {
+		t.Errorf("expected 7 values, got %v", numbersOpt.Value())
+	}
+}
+
+func Test141(t *testing.T) {
+	parser := NewParserUser("myapp", "")
+	sizesOpt := parser.Reals("sizes", "sizes help")
+	sizesOpt.ValueCount = FourValues
+	if err := parser.ParseLine("-s 1 2 3 4"); err != nil {
+		t.Fatal(err)
+	}
+	exitFunc = handleTextExitFunc
+	defer handleTextAndQuit(
+		"error #102: expected four values for sizes, got 3", t)
+	_ = parser.ParseLine("-s 1 2 3")
+}
+
+func Test142(t *testing.T) {
+	parser := NewParserUser("myapp", "")
+	pairOpt := parser.Strs("pair", "pair help")
+	pairOpt.MinValues = 2
+	pairOpt.MaxValues = 2
+	exitFunc = handleTextExitFunc
+	defer handleTextAndQuit(
+		"error #102: expected exactly 2 values for pair, got none", t)
+	_ = parser.ParseLine("-p")
+}
+
+func newArgOrderTestParser(order ArgOrder) (Parser, *FlagOption,
+	*IntOption,
+) {
+	parser := NewParserUser("myapp", "")
+	parser.ArgOrder = order
+	verboseOpt := parser.Flag("verbose", "verbose help")
+	countOpt := parser.Int("count", "count help", 1)
+	return parser, verboseOpt, countOpt
+}
+
+func Test143(t *testing.T) {
+	parser, verboseOpt, countOpt := newArgOrderTestParser(GNUOrder)
+	if err := parser.ParseLine("file1 -v file2 -c 5 file3"); err != nil {
+		t.Fatal(err)
+	}
+	if !verboseOpt.Value() || countOpt.Value() != 5 {
+		t.Errorf("expected -v and -c 5, got %t %d", verboseOpt.Value(),
+			countOpt.Value())
+	}
+	if e := expectEqualSlice([]string{"file1", "file2", "file3"},
+		parser.Positionals, "positionals"); e != "" {
+		t.Error(e)
+	}
+	if err := parser.ParseLine("file1 -- -v file2"); err != nil {
+		t.Fatal(err)
+	}
+	if verboseOpt.Value() {
+		t.Error("expected -v after -- to be a positional")
+	}
+	if e := expectEqualSlice([]string{"file1", "-v", "file2"},
+		parser.Positionals, "positionals"); e != "" {
+		t.Error(e)
+	}
+}
+
+func Test144(t *testing.T) {
+	parser, verboseOpt, countOpt := newArgOrderTestParser(POSIXOrder)
+	if err := parser.ParseLine("-c 5 file1 -v --count=3 -x"); err != nil {
+		t.Fatal(err)
+	}
+	if verboseOpt.Value() || countOpt.Value() != 5 {
+		t.Errorf("expected no -v and -c 5, got %t %d", verboseOpt.Value(),
+			countOpt.Value())
+	}
+	if e := expectEqualSlice([]string{"file1", "-v", "--count=3", "-x"},
+		parser.Positionals, "positionals"); e != "" {
+		t.Error(e)
+	}
+}
+
+func Test145(t *testing.T) {
+	t.Setenv("POSIXLY_CORRECT", "")
+	parser, verboseOpt, _ := newArgOrderTestParser(GNUOrder)
+	if err := parser.ParseLine("file1 -v"); err != nil {
+		t.Fatal(err)
+	}
+	if verboseOpt.Value() {
+		t.Error("expected POSIXLY_CORRECT to make -v a positional")
+	}
+	parser.ArgOrder = PassUnknownOrder // not affected by POSIXLY_CORRECT
+	if err := parser.ParseLine("file1 -v"); err != nil {
+		t.Fatal(err)
+	}
+	if !verboseOpt.Value() {
+		t.Error("expected -v to be an option")
+	}
+}
+
+func Test146(t *testing.T) {
+	parser, verboseOpt, countOpt := newArgOrderTestParser(
+		PassUnknownOrder)
+	line := "file1 -v --other=x -y value -c 7 -2"
+	if err := parser.ParseLine(line); err != nil {
+		t.Fatal(err)
+	}
+	if !verboseOpt.Value() || countOpt.Value() != 7 {
+		t.Errorf("expected -v and -c 7, got %t %d", verboseOpt.Value(),
+			countOpt.Value())
+	}
+	if e := expectEqualSlice([]string{"file1", "--other=x", "-y", "value",
+		"-2"}, parser.Positionals, "positionals"); e != "" {
+		t.Error(e)
+	}
+	exitFunc = testingExitFunc
+	defer expectPanic(eUnrecognizedOption, t)
+	_ = parser.ParseLine("-y file1")
+}
+
+func Test147(t *testing.T) {
+	parser, verboseOpt, countOpt := newArgOrderTestParser(GNUOrder)
+	parser.CollectUnknown = true
+	namesOpt := parser.Strs("names", "names help")
+	line := "--jobs=4 -v -Wall file1 --debug -c 3 -q -n a b --fast x"
+	if err := parser.ParseLine(line); err != nil {
+		t.Fatal(err)
+	}
+	if !verboseOpt.Value() || countOpt.Value() != 3 {
+		t.Errorf("expected -v and -c 3, got %t %d", verboseOpt.Value(),
+			countOpt.Value())
+	}
+	if e := expectEqualSlice([]string{"--jobs=4", "-Wall", "--debug", "-q",
+		"--fast"}, parser.Unknown, "unknown"); e != "" {
+		t.Error(e)
+	}
+	if e := expectEqualSlice([]string{"a", "b"}, namesOpt.Value(),
+		"names"); e != "" {
+		t.Error(e)
+	}
+	if e := expectEqualSlice([]string{"file1", "x"}, parser.Positionals,
+		"positionals"); e != "" {
+		t.Error(e)
+	}
+	if err := parser.ParseLine("-vx=1 -- --not-an-option"); err != nil {
+		t.Fatal(err)
+	}
+	if e := expectEqualSlice([]string{"-x=1"}, parser.Unknown,
+		"unknown"); e != "" {
+		t.Error(e)
+	}
+	if e := expectEqualSlice([]string{"--not-an-option"},
+		parser.Positionals, "positionals"); e != "" {
+		t.Error(e)
+	}
+}
+
+func Test148(t *testing.T) {
+	exitFunc = testingExitFunc
+	filename := filepath.Join(t.TempDir(), "x.gz")
+	if err := os.WriteFile(filename, []byte("not gzip"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	parser := NewParser()
+	infileOpt := parser.InputFile("infile", "infile help", "-")
+	if err := parser.ParseLine("-i " + filename); err != nil {
+		t.Fatal(err)
+	}
+	reader := infileOpt.Value()
+	if _, err := io.ReadAll(reader); err == nil {
+		t.Error("expected an error reading invalid gzip")
+	}
+	if err := reader.Close(); err != nil {
+		t.Error(err)
+	}
+	if _, err := reader.Read(make([]byte, 1)); err == nil {
+		t.Error("expected an error reading after close")
+	}
+}
+
+func Test149(t *testing.T) {
+	exitFunc = testingExitFunc
+	parser := NewParser()
+	addrs := []netip.Addr{netip.MustParseAddr("127.0.0.1")}
+	Texts(&parser, "addr", "addr help", &addrs)
+	if err := parser.ParseArgs(nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(addrs) != 1 || addrs[0].String() != "127.0.0.1" {
+		t.Errorf("expected the default address, got %v", addrs)
+	}
+	if err := parser.ParseLine("-a 192.168.1.7 192.168.1.8"); err != nil {
+		t.Fatal(err)
+	}
+	if len(addrs) != 2 {
+		t.Errorf("expected 2 addresses, got %v", addrs)
+	}
+	if err := parser.ParseLine(""); err != nil {
+		t.Fatal(err)
+	}
+	if len(addrs) != 1 || addrs[0].String() != "127.0.0.1" {
+		t.Errorf("expected the default address restored, got %v", addrs)
+	}
+}
+
+func Test150(t *testing.T) {
+	exitFunc = testingExitFunc
+	parser := NewParser()
+	cpusOpt := parser.IntRanges("cpus", "cpus help")
+	line := "-c 9223372036854775806-9223372036854775807,3"
+	if err := parser.ParseLine(line); err != nil {
+		t.Fatal(err)
+	}
+	cpus, _ := cpusOpt.Value()
+	if e := expectEqualSlice([]int{3, math.MaxInt - 1, math.MaxInt}, cpus,
+		"cpus"); e != "" {
+		t.Error(e)
+	}
+	if !cpusOpt.Contains(math.MaxInt) || cpusOpt.Contains(4) {
+		t.Error("unexpected Contains result")
+	}
+	for _, line := range []string{"-c 0-1000000000000",
+		"-c 0-500000,600000-1200000"} {
+		func() {
+			exitFunc = testingExitFunc
+			defer expectPanic(eInvalidValue, t)
+			_ = parser.ParseLine(line)
+		}()
+	}
+}
+
+func Test151(t *testing.T) {
+	exitFunc = testingExitFunc
+	fs := flag.NewFlagSet("legacy", flag.ContinueOnError)
+	verbose := fs.Bool("v", false, "verbose help")
+	name := fs.String("name", "anon", "name help")
+	fs.Int("count", 1, "count help")
+	parser := FromFlagSet(fs)
+	roundTrip := parser.ToFlagSet()
+	roundTrip.SetOutput(io.Discard)
+	if err := roundTrip.Parse([]string{"-v", "--name=y"}); err != nil {
+		t.Fatal(err)
+	}
+	if flag := roundTrip.Lookup("name"); flag == nil ||
+		flag.Value.String() != "y" {
+		t.Errorf("expected name=y, got %v", flag)
+	}
+	if flag := roundTrip.Lookup("v"); flag == nil ||
+		flag.Value.String() != "true" {
+		t.Errorf("expected v=true, got %v", flag)
+	}
+	func() {
+		defer expectPanic(eInvalidValue, t)
+		_ = parser.ParseLine("--name y --count zz")
+	}()
+	if *name != "anon" || *verbose {
+		t.Errorf("expected unchanged flags, got %q %t", *name, *verbose)
+	}
+}
+
+func Test152(t *testing.T) {
+	exitFunc = testingExitFunc
+	parser := NewParser()
+	var cfg struct {
+		Color bool `default:"true"`
+	}
+	if err := Bind(&parser, &cfg); err == nil ||
+		!strings.HasPrefix(err.Error(), "#111:") {
+		t.Errorf("expected an invalid binding error, got %v", err)
+	}
+	summary := true
+	parser.FlagVar(&summary, "summary", "summary help")
+	if err := parser.ParseLine(""); err != nil {
+		t.Fatal(err)
+	}
+	if !summary {
+		t.Error("expected true default kept")
+	}
+}
+
+func Test153(t *testing.T) {
+	exitFunc = testingExitFunc
+	dir := t.TempDir()
+	infile := filepath.Join(dir, "in.txt")
+	if err := os.WriteFile(infile, []byte("input"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	parser := NewParser()
+	var count int
+	var ratio float64
+	var format string
+	var pages []int
+	var reader io.ReadCloser
+	var writer io.WriteCloser
+	parser.IntInRangeVar(&count, "count", "count help", 1, 9, 2)
+	parser.RealInRangeVar(&ratio, "ratio", "ratio help", 0, 1, 0.5)
+	parser.ChoiceVar(&format, "format", "format help",
+		[]string{"csv", "json"}, "csv")
+	parser.IntRangesVar(&pages, "pages", "pages help")
+	parser.InputFileVar(&reader, "infile", "infile help", "")
+	parser.OutputFileVar(&writer, "outfile", "outfile help", "")
+	line := "-c 7 -f json -p 1-3 -i " + infile
+	if err := parser.ParseLine(line); err != nil {
+		t.Fatal(err)
+	}
+	if count != 7 || !realEqual(ratio, 0.5) || format != "json" {
+		t.Errorf("unexpected values: %d %g %q", count, ratio, format)
+	}
+	if e := expectEqualSlice([]int{1, 2, 3}, pages, "pages"); e != "" {
+		t.Error(e)
+	}
+	if writer != nil {
+		t.Error("expected no writer")
+	}
+	defer reader.Close()
+	data, err := io.ReadAll(reader)
+	if err != nil || string(data) != "input" {
+		t.Errorf("expected \"input\", got %q %v", data, err)
+	}
+}
+
+func Test154(t *testing.T) {
+	tty = false
+	exitFunc = handleTextExitFunc
+	parser := NewParserUser("myapp", "")
+	parser.Positional("SRC", "")
+	parser.Positional("DST", "")
+	expected := `usage: myapp [OPTIONS] <SRC> <DST>
+
+
+positional arguments:
+  SRC
+  DST
+
+optional arguments:
+  -h, --help  Show help and quit.`
+	defer handleTextAndQuit(expected, t)
+	if err := parser.ParseLine("-h"); err != nil {
+		t.Error(err)
+	}
+}
+
+func newSubcommandTestParser() (Parser, *FlagOption, *FlagOption,
+	*StrOption) {
+	parser := NewParserUser("myapp", "")
+	verboseOpt := parser.Flag("verbose", "verbose help")
+	add := parser.Subcommand("add", "Add items.")
+	forceOpt := add.Flag("force", "force help")
+	list := parser.Subcommand("list", "List items.")
+	formatOpt := list.Str("format", "format help", "csv")
+	return parser, verboseOpt, forceOpt, formatOpt
+}
+
+func Test155(t *testing.T) {
+	exitFunc = testingExitFunc
+	parser, verboseOpt, forceOpt, formatOpt := newSubcommandTestParser()
+	if err := parser.ParseLine("-v add -f x y"); err != nil {
+		t.Fatal(err)
+	}
+	add := parser.subcommands[0].parser
+	if parser.Command() != "add" || !verboseOpt.Value() ||
+		!forceOpt.Value() || len(parser.Positionals) != 0 {
+		t.Errorf("unexpected parse %q %t %t %v", parser.Command(),
+			verboseOpt.Value(), forceOpt.Value(), parser.Positionals)
+	}
+	if e := expectEqualSlice([]string{"x", "y"}, add.Positionals,
+		"positionals"); e != "" {
+		t.Error(e)
+	}
+	if err := parser.ParseLine("list --format json"); err != nil {
+		t.Fatal(err)
+	}
+	if parser.Command() != "list" || verboseOpt.Value() ||
+		forceOpt.Value() || formatOpt.Value() != "json" ||
+		len(add.Positionals) != 0 {
+		t.Errorf("unexpected parse %q %t %t %q", parser.Command(),
+			verboseOpt.Value(), forceOpt.Value(), formatOpt.Value())
+	}
+}
+
+func Test156(t *testing.T) {
+	exitFunc = testingExitFunc
+	parser, _, _, _ := newSubcommandTestParser()
+	defer expectPanic(eInvalidCommand, t)
+	_ = parser.ParseLine("-v remove x")
+}
+
+func Test157(t *testing.T) {
+	exitFunc = testingExitFunc
+	parser, _, _, _ := newSubcommandTestParser()
+	defer expectPanic(eInvalidCommand, t)
+	_ = parser.ParseLine("-v")
+}
+
+func Test158(t *testing.T) {
+	tty = false
+	exitFunc = handleTextExitFunc
+	parser, _, _, _ := newSubcommandTestParser()
+	expected := `usage: myapp [OPTIONS] <COMMAND> ...
+
+commands:
+  add   Add items.
+  list  List items.
+
+optional arguments:
+  -v, --verbose  verbose help
+  -h, --help     Show help and quit.`
+	defer handleTextAndQuit(expected, t)
+	if err := parser.ParseLine("-h"); err != nil {
+		t.Error(err)
+	}
+}
+
+func Test159(t *testing.T) {
+	t.Parallel()
+	var stdout, stderr strings.Builder
+	code := -1
+	parser := NewParserUser("myapp", "")
+	parser.Stdout, parser.Stderr = &stdout, &stderr
+	parser.Exit = func(exitCode int) { code = exitCode }
+	parser.Color = ColorNever
+	add := parser.Subcommand("add", "Add items.")
+	add.PositionalCount = OnePositional
+	err := parser.ParseLine("add")
+	var exitErr *ExitError
+	if !errors.As(err, &exitErr) || code != 2 || stdout.Len() != 0 ||
+		!strings.Contains(stderr.String(), "for help run: myapp add --help") {
+		t.Errorf("unexpected result %v %d %q", err, code, stderr.String())
+	}
+}
+
+type bindTestLevel int8
+
+type bindTestCommands struct {
+	Verbose bool
+	Add     struct {
+		Force   bool          `help:"Replace existing items"`
+		Level   bindTestLevel `clip:"level,l" default:"3"`
+		Port    uint16        `clip:"port,p"`
+		Timeout time.Duration `clip:"timeout,t" default:"1s"`
+		Ratio   float32       `clip:"ratio,r" range:"0,1"`
+		Sizes   []int64       `clip:"sizes,s"`
+		Waits   []time.Duration
+	} `cmd:"" help:"Add items."`
+	List struct {
+		Format string `default:"csv" choices:"csv,json"`
+	} `cmd:"ls" help:"List items."`
+}
+
+func Test160(t *testing.T) {
+	exitFunc = testingExitFunc
+	parser := NewParserUser("myapp", "")
+	var cfg bindTestCommands
+	if err := Bind(&parser, &cfg); err != nil {
+		t.Fatal(err)
+	}
+	line := "-v add -f -p 8080 -t 2m -r 0.25 -s 1 5000000000 -w 1s 2h"
+	if err := parser.ParseLine(line); err != nil {
+		t.Fatal(err)
+	}
+	if parser.Command() != "add" || !cfg.Verbose || !cfg.Add.Force ||
+		cfg.Add.Level != 3 || cfg.Add.Port != 8080 ||
+		cfg.Add.Timeout != 2*time.Minute || cfg.Add.Ratio != 0.25 ||
+		cfg.List.Format != "csv" {
+		t.Errorf("unexpected config %+v", cfg)
+	}
+	if !slices.Equal(cfg.Add.Sizes, []int64{1, 5_000_000_000}) ||
+		!slices.Equal(cfg.Add.Waits, []time.Duration{time.Second,
+			2 * time.Hour}) {
+		t.Errorf("unexpected slices %v %v", cfg.Add.Sizes, cfg.Add.Waits)
+	}
+	if err := parser.ParseLine("ls -f json"); err != nil {
+		t.Fatal(err)
+	}
+	if parser.Command() != "ls" || cfg.List.Format != "json" {
+		t.Errorf("unexpected config %+v", cfg)
+	}
+}
+
+func Test161(t *testing.T) {
+	exitFunc = testingExitFunc
+	parser := NewParserUser("myapp", "")
+	var cfg bindTestCommands
+	if err := Bind(&parser, &cfg); err != nil {
+		t.Fatal(err)
+	}
+	defer expectPanic(eInvalidValue, t)
+	_ = parser.ParseLine("add -l 200")
+}
+
+func Test162(t *testing.T) {
+	parser := NewParserUser("myapp", "")
+	cfg := struct {
+		Small uint8 `range:"0,300"`
+	}{}
+	if err := Bind(&parser, &cfg); err == nil {
+		t.Error("expected an out of limits range error")
+	}
+}
+
+func Test163(t *testing.T) {
+	parser, verboseOpt, countOpt := newArgOrderTestParser(POSIXOrder)
+	parser.ArgOrder = ArgOrder(0) // the default
+	if err := parser.ParseLine("file1 -v -c 2"); err != nil {
+		t.Fatal(err)
+	}
+	if verboseOpt.Value() || countOpt.Value() == 2 {
+		t.Error("expected the default order to be POSIXOrder")
+	}
+	parser.ArgOrder = PassUnknownOrder
+	if err := parser.ParseLine("file1 -vX -vc 2"); err != nil {
+		t.Fatal(err)
+	}
+	if !verboseOpt.Value() || countOpt.Value() != 2 {
+		t.Errorf("expected -v and -c 2, got %t %d", verboseOpt.Value(),
+			countOpt.Value())
+	}
+	if e := expectEqualSlice([]string{"file1", "-vX"}, parser.Positionals,
+		"positionals"); e != "" {
+		t.Error(e)
+	}
+}
+
+func Test164(t *testing.T) {
+	parser := newTopicTestParser()
+	verboseOpt := parser.optionForTopic("verbose")
+	formatOpt := parser.optionForTopic("format")
+	for _, item := range []struct {
+		cmdline  string
+		option   optioner
+		expected bool
+	}{
+		{"-vfjson data.txt", formatOpt, true},
+		{"-vfjson data.txt", verboseOpt, true},
+		{"-fv data.txt", verboseOpt, false},
+		{"-f=json data.txt", formatOpt, true},
+		{"--verbose data.txt", formatOpt, false},
+	} {
+		if actual := parser.usesOption(item.cmdline,
+			item.option); actual != item.expected {
+			t.Errorf("expected %t for %s in %q, got %t", item.expected,
+				item.option.LongName(), item.cmdline, actual)
+		}
+	}
+}
+
+func Test165(t *testing.T) {
+	errTTY = true
+	var hint string
+	exitFunc = func(_ int, _, h string) { hint = h }
+	defer func() {
+		errTTY = false
+		exitFunc = defaultExitFunc
+	}()
+	parser := NewParserUser("myapp", "")
+	parser.PositionalCount = ZeroPositionals
+	parser.ColorName = "color"
+	_ = parser.ParseLine("x")
+	if !strings.Contains(hint, "\x1B[") {
+		t.Errorf("expected a styled hint, got %q", hint)
+	}
+	_ = parser.ParseLine("--color=never x")
+	if hint != "for help run: myapp --help" {
+		t.Errorf("expected an unstyled hint, got %q", hint)
+	}
+	parser.Theme.Hint = ""
+	_ = parser.ParseLine("x")
+	if hint != "for help run: myapp --help" {
+		t.Errorf("expected an unstyled themed hint, got %q", hint)
+	}
+}
+
+func Test166(t *testing.T) {
+	dir := t.TempDir()
+	parser := NewParserUser("myapp", "")
+	outdirOpt := parser.Path("outdir", "outdir help", "")
+	outdirOpt.Checks = PathMustBeDir | PathWritable
+	if err := parser.ParseLine("-o " + dir); err != nil {
+		t.Fatal(err)
+	}
+	if entries, err := os.ReadDir(dir); err != nil || len(entries) != 0 {
+		t.Errorf("expected the writable check to create nothing, got %v",
+			entries)
+	}
+	if onWindows || os.Geteuid() == 0 {
+		return // permissions aren't enforced
+	}
+	if err := os.Chmod(dir, 0o555); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = os.Chmod(dir, 0o755) }()
+	exitFunc = testingExitFunc
+	defer expectPanic(eInvalidValue, t)
+	_ = parser.ParseLine("-o " + dir)
+}
+
+func Test167(t *testing.T) {
+	tty = true
+	defer func() { tty = false }()
+	shell, stdout, _, _ := newTestShell("help add\nhelp\n")
+	shell.Color = ColorNever
+	shell.command("add").parser.ColorName = "color"
+	if err := shell.Run(); err != nil {
+		t.Fatal(err)
+	}
+	output := stdout.String()
+	if strings.Contains(output, "\x1B[") {
+		t.Errorf("expected unstyled output, got %q", output)
+	}
+	if !strings.Contains(output, "  --color ") {
+		t.Errorf("expected the first help NAME to show --color, got %q",
+			output)
+	}
+}
+
+func Test169(t *testing.T) {
+	newParser := func() Parser {
+		parser := NewParserUser("myapp", "")
+		add := parser.Subcommand("add", "Add items.")
+		add.Int("count", "How many", 1)
+		parser.Example("add --count=x", "Add x items.")
+		return parser
+	}
+	if err := CheckExamples(newParser); err == nil ||
+		!strings.Contains(err.Error(), "add --count=x") {
+		t.Errorf("expected an invalid example error, got %v", err)
+	}
+}
+
+func Test170(t *testing.T) {
+	parser := NewParserUser("myapp", "")
+	names := []string{"a", "b"}
+	parser.StrsVar(&names, "names", "Names")
+	cfg := struct {
+		Sizes []int `help:"Sizes"`
+	}{Sizes: []int{1, 2}}
+	if err := Bind(&parser, &cfg); err != nil {
+		t.Fatal(err)
+	}
+	if err := parser.ParseLine("-n x y -s 7"); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(names, []string{"x", "y"}) ||
+		!slices.Equal(cfg.Sizes, []int{7}) {
+		t.Errorf("expected [x y] [7], got %v %v", names, cfg.Sizes)
+	}
+	if err := parser.ParseLine(""); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(names, []string{"a", "b"}) ||
+		!slices.Equal(cfg.Sizes, []int{1, 2}) {
+		t.Errorf("expected [a b] [1 2], got %v %v", names, cfg.Sizes)
+	}
+}
+
+type upperText struct{ text string }
+
+func (me *upperText) UnmarshalText(text []byte) error {
+	me.text = strings.ToUpper(string(text))
+	return nil
+}
+
+func Test171(t *testing.T) {
+	parser := NewParserUser("myapp", "")
+	value := upperText{"DEFAULT"}
+	parser.Text("word", "A word", &value)
+	if err := parser.ParseLine("-w hello"); err != nil {
+		t.Fatal(err)
+	}
+	if value.text != "HELLO" {
+		t.Errorf("expected HELLO, got %q", value.text)
+	}
+	if err := parser.ParseLine(""); err != nil {
+		t.Fatal(err)
+	}
+	if value.text != "DEFAULT" {
+		t.Errorf("expected DEFAULT, got %q", value.text)
+	}
+}
+
+func Test172(t *testing.T) {
+	parser := NewParserUser("myapp", "")
+	includeOpt := parser.Strs("include", "include help")
+	includeOpt.MinValues = 3
+	if argText := optArgText(includeOpt); argText !=
+		" <INCLUDE1> <INCLUDE2> <INCLUDE3> [INCLUDE4 ...]" {
+		t.Errorf("unexpected arg text %q", argText)
+	}
+	if err := parser.ParseLine("-i a b c d"); err != nil {
+		t.Fatal(err)
+	}
+	numbersOpt := parser.Ints("numbers", "numbers help")
+	numbersOpt.MaxValues = 5
+	if argText := optArgText(numbersOpt); argText !=
+		" <NUMBERS1> [NUMBERS2 [NUMBERS3 [NUMBERS4 [NUMBERS5]]]]" {
+		t.Errorf("unexpected arg text %q", argText)
+	}
+	exitFunc = handleTextExitFunc
+	defer handleTextAndQuit(
+		"error #102: expected 1 to 5 values for numbers, got none", t)
+	_ = parser.ParseLine("-n")
+}
+
+func Test173(t *testing.T) {
+	parser := NewParserUser("myapp", "")
+	pairOpt := parser.Reals("pair", "pair help")
+	pairOpt.ValueCount = TwoValues
+	pairOpt.MinValues = 3
+	exitFunc = testingExitFunc
+	defer expectPanic(eInvalidValueCount, t)
+	_ = parser.ParseLine("")
+}
+
+func Test174(t *testing.T) {
+	fs := flag.NewFlagSet("myapp", flag.ContinueOnError)
+	color := fs.Bool("color", true, "Use color")
+	quiet := fs.Bool("q", false, "Be quiet")
+	parser := FromFlagSet(fs)
+	if err := parser.ParseLine("--no-color -q"); err != nil {
+		t.Fatal(err)
+	}
+	if *color || !*quiet {
+		t.Errorf("expected color=false quiet=true, got %t %t", *color,
+			*quiet)
+	}
+	if err := parser.ParseLine(""); err != nil {
+		t.Fatal(err)
+	}
+	if *color || !*quiet {
+		t.Errorf("expected unchanged flags, got %t %t", *color, *quiet)
+	}
+	exitFunc = handleTextExitFunc
+	defer handleTextAndQuit(
+		"error #107: unexpected value \"false\" for flag --color", t)
+	_ = parser.ParseLine("--color=false")
+}
+
+func Test175(t *testing.T) {
+	parser := NewParserUser("myapp", "")
+	parser.Flag("verbose", "verbose help")
+	exitFunc = handleTextExitFunc
+	defer handleTextAndQuit(
+		"error #107: unexpected value \"x\" for flag -v", t)
+	_ = parser.ParseLine("-v=x")
+}
+
+func Test176(t *testing.T) {
+	parser := NewParserUser("myapp", "")
+	cfg := struct {
+		Addrs []netip.Addr `help:"Addresses" default:"127.0.0.1,::1"`
+	}{}
+	if err := Bind(&parser, &cfg); err != nil {
+		t.Fatal(err)
+	}
+	if err := parser.ParseLine("-a 192.168.1.7 10.0.0.1"); err != nil {
+		t.Fatal(err)
+	}
+	expected := []netip.Addr{netip.MustParseAddr("192.168.1.7"),
+		netip.MustParseAddr("10.0.0.1")}
+	if !slices.Equal(cfg.Addrs, expected) {
+		t.Errorf("expected %v, got %v", expected, cfg.Addrs)
+	}
+	if err := parser.ParseLine(""); err != nil {
+		t.Fatal(err)
+	}
+	expected = []netip.Addr{netip.MustParseAddr("127.0.0.1"),
+		netip.MustParseAddr("::1")}
+	if !slices.Equal(cfg.Addrs, expected) {
+		t.Errorf("expected %v, got %v", expected, cfg.Addrs)
+	}
+	exitFunc = testingExitFunc
+	defer expectPanic(eInvalidValue, t)
+	_ = parser.ParseLine("-a 300.1.1.1")
+}
+
+func Test177(t *testing.T) {
+	parser := NewParserUser("myapp", "")
+	parser.IntRangesInRange("offsets", "offsets help", -5, 5)
+	exitFunc = handleTextExitFunc
+	defer handleTextAndQuit(
+		"error #102: invalid int ranges limits -5 to 5 for offsets", t)
+	_ = parser.ParseLine("-o 1-2")
+}
+
+func Test178(t *testing.T) {
+	parser := NewParserUser("myapp", "")
+	count := parser.PositionalInt("COUNT", "How many.", 1)
+	count.MakeOptional()
+	parser.Positional("DST", "The destination.")
+	exitFunc = handleTextExitFunc
+	defer handleTextAndQuit("error #115: required positional DST can't "+
+		"follow optional positional COUNT", t)
+	_ = parser.ParseLine("") // a definition error, not a count error
+}
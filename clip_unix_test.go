@@ -0,0 +1,71 @@
+// Copyright © 2022 Mark Summerfield. All rights reserved.
+// License: Apache-2.0
+
+//go:build unix
+
+package clip
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"golang.org/x/sys/unix"
+)
+
+func Test168(t *testing.T) {
+	dir := t.TempDir()
+	fifo := filepath.Join(dir, "out.fifo")
+	if err := unix.Mkfifo(fifo, 0o600); err != nil {
+		t.Skip(err)
+	}
+	received := make(chan string)
+	go func() {
+		data, _ := os.ReadFile(fifo)
+		received <- string(data)
+	}()
+	parser := NewParserUser("myapp", "")
+	outfileOpt := parser.OutputFile("outfile", "outfile help", "")
+	if err := parser.ParseLine("-o " + fifo); err != nil {
+		t.Fatal(err)
+	}
+	writer := outfileOpt.Value()
+	fmt.Fprint(writer, "through")
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if text := <-received; text != "through" {
+		t.Errorf("expected \"through\", got %q", text)
+	}
+	if info, err := os.Lstat(fifo); err != nil ||
+		info.Mode()&os.ModeNamedPipe == 0 {
+		t.Errorf("expected the FIFO to be kept, got %v %v", info, err)
+	}
+	target := filepath.Join(dir, "target.txt")
+	link := filepath.Join(dir, "link.txt")
+	if err := os.Symlink(target, link); err != nil {
+		t.Fatal(err)
+	}
+	if err := parser.ParseLine("-o " + link); err != nil {
+		t.Fatal(err)
+	}
+	writer = outfileOpt.Value()
+	fmt.Fprint(writer, "linked")
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	file, err := os.Open(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	if data, _ := io.ReadAll(file); string(data) != "linked" {
+		t.Errorf("expected \"linked\" in the target, got %q", data)
+	}
+	if info, err := os.Lstat(link); err != nil ||
+		info.Mode()&os.ModeSymlink == 0 {
+		t.Errorf("expected the symlink to be kept, got %v %v", info, err)
+	}
+}
@@ -0,0 +1,130 @@
+// Copyright © 2022 Mark Summerfield. All rights reserved.
+// License: Apache-2.0
+
+// Package cliptest provides helpers for testing clip-based command line
+// interfaces: [Run] parses arguments and captures the exit code and
+// output, and [Golden] compares output with golden files (which are
+// updated if [Update] is true or the tests are run with -update).
+//
+//	func newParser() clip.Parser { ... }
+//
+//	func TestHelp(t *testing.T) {
+//		result := cliptest.Run(newParser, "--help")
+//		cliptest.Golden(t, "help", result.Stdout)
+//	}
+package cliptest
+
+import (
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mark-summerfield/clip"
+)
+
+// Update if true means that [Golden] writes the golden files rather than
+// compares with them. Golden files are also written if the tests are run
+// with -update and the test package defines an update flag, e.g.,
+//
+//	var _ = flag.Bool("update", false, "update golden files")
+//
+// (This package doesn't define the flag itself since it would clash with
+// test packages that already do.)
+var Update bool
+
+// Result holds the outcome of parsing some arguments with [Run].
+type Result struct {
+	Code   int          // The exit code (0 if the parser didn't exit).
+	Exited bool         // True if the parser would have quit.
+	Stdout string       // What the parser wrote to stdout, e.g., help.
+	Stderr string       // What the parser wrote to stderr, e.g., errors.
+	Err    error        // The parse's error (an *clip.ExitError if Exited).
+	Parser *clip.Parser // The parser (e.g., for its Positionals).
+}
+
+// Run creates a parser by calling build and uses it to parse the given
+// arguments, returning the result. The parser's output is captured rather
+// than written to stdout and stderr, and it doesn't end the program. If
+// the parser's Color is clip.ColorAuto it is set to clip.ColorNever so
+// that the output is plain text. The values of the parser's options can
+// be checked by using the options that build created.
+func Run(build func() clip.Parser, args ...string) Result {
+	var stdout, stderr strings.Builder
+	var result Result
+	parser := build()
+	parser.Stdout = &stdout
+	parser.Stderr = &stderr
+	parser.Exit = func(code int) {
+		result.Code = code
+		result.Exited = true
+	}
+	if parser.Color == clip.ColorAuto {
+		parser.Color = clip.ColorNever
+	}
+	result.Err = parser.ParseArgs(args)
+	var exitErr *clip.ExitError
+	if errors.As(result.Err, &exitErr) {
+		result.Code = exitErr.Code
+		result.Exited = true
+	}
+	result.Stdout = stdout.String()
+	result.Stderr = stderr.String()
+	result.Parser = &parser
+	return result
+}
+
+// Golden fails the test if got differs from the contents of the golden
+// file testdata/name.golden. If [Update] is true or the tests are run with
+// -update, got is written to the golden file instead.
+func Golden(t testing.TB, name, got string) {
+	t.Helper()
+	filename := filepath.Join("testdata", name+".golden")
+	if updating() {
+		if err := os.MkdirAll("testdata", 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filename, []byte(got), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	expected, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatalf("%s (run the tests with -update to create it)", err)
+	}
+	if got != string(expected) {
+		t.Errorf("output != %s:\nEXP: %s\nACT: %s", filename, expected, got)
+	}
+}
+
+// Returns true if Update is true or if there's an update flag that's true.
+func updating() bool {
+	if Update {
+		return true
+	}
+	if f := flag.Lookup("update"); f != nil {
+		if getter, ok := f.Value.(flag.Getter); ok {
+			value, ok := getter.Get().(bool)
+			return ok && value
+		}
+	}
+	return false
+}
+
+// GoldenHelp runs the parser created by build with the given help option
+// (e.g., "--help" or "-h") and compares its output with the golden file
+// testdata/name.golden as for [Golden].
+func GoldenHelp(t testing.TB, build func() clip.Parser, name,
+	helpOption string,
+) {
+	t.Helper()
+	result := Run(build, helpOption)
+	if !result.Exited || result.Code != 0 {
+		t.Errorf("expected %s to exit with code 0, got %d: %s", helpOption,
+			result.Code, result.Stderr)
+	}
+	Golden(t, name, result.Stdout)
+}
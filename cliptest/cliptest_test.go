@@ -0,0 +1,88 @@
+// Copyright © 2022 Mark Summerfield. All rights reserved.
+// License: Apache-2.0
+
+package cliptest
+
+import (
+	"flag"
+	"testing"
+
+	"github.com/mark-summerfield/clip"
+)
+
+var countOpt *clip.IntOption
+
+// Checks that a test package can define its own -update flag.
+var updateGolden = flag.Bool("update", false, "update golden files")
+
+func newParser() clip.Parser {
+	parser := clip.NewParserUser("myapp", "1.2.3")
+	parser.LongDesc = "Counts things."
+	countOpt = parser.IntInRange("count", "How many", 1, 10, 5)
+	parser.Flag("quiet", "Be quiet")
+	return parser
+}
+
+func Test001(t *testing.T) {
+	result := Run(newParser, "--count=7", "a.txt", "b.txt")
+	if result.Exited || result.Err != nil {
+		t.Fatalf("unexpected exit %d: %v", result.Code, result.Err)
+	}
+	if countOpt.Value() != 7 {
+		t.Errorf("expected count=7, got %d", countOpt.Value())
+	}
+	if len(result.Parser.Positionals) != 2 {
+		t.Errorf("expected 2 positionals, got %v", result.Parser.Positionals)
+	}
+}
+
+func Test002(t *testing.T) {
+	result := Run(newParser, "--count=70")
+	if !result.Exited || result.Code != 2 {
+		t.Errorf("expected exit code 2, got %d", result.Code)
+	}
+	Golden(t, "count-error", result.Stderr)
+	result = Run(newParser, "-v")
+	if !result.Exited || result.Code != 0 || result.Stdout != "myapp v1.2.3\n" {
+		t.Errorf("unexpected version result %+v", result)
+	}
+}
+
+func Test003(t *testing.T) {
+	GoldenHelp(t, newParser, "help", "--help")
+}
+
+func newSubcommandParser() clip.Parser {
+	parser := clip.NewParserUser("myapp", "")
+	add := parser.Subcommand("add", "Add items.")
+	add.Int("count", "How many", 1)
+	return parser
+}
+
+func Test004(t *testing.T) {
+	result := Run(newSubcommandParser, "add", "--count=x")
+	if !result.Exited || result.Code != 2 {
+		t.Errorf("expected exit code 2, got %d", result.Code)
+	}
+	expected := "error #102: option count's value of \"x\" isn't an int\n" +
+		"for help run: myapp add --help\n"
+	if result.Stderr != expected {
+		t.Errorf("expected stderr %q, got %q", expected, result.Stderr)
+	}
+}
+
+func Test005(t *testing.T) {
+	if updating() != *updateGolden {
+		t.Errorf("expected updating() to match -update=%t", *updateGolden)
+	}
+	if *updateGolden {
+		return
+	}
+	if err := flag.Set("update", "true"); err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = flag.Set("update", "false") }()
+	if !updating() {
+		t.Error("expected updating() to be true for -update")
+	}
+}
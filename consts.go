@@ -4,6 +4,7 @@
 package clip
 
 const NoShortName = 0 // Use this for options that don't have short names
+const Unlimited = -1  // Use this for a range's maximum if there isn't one
 const columnGap = "  "
 
 // These take an option's name and the given string value and return a
@@ -11,6 +12,7 @@ const columnGap = "  "
 type IntValidator func(string, string) (int, string)
 type RealValidator func(string, string) (float64, string)
 type StrValidator func(string, string) (string, string)
+type IntRangesValidator func(string, string) ([]IntRange, string)
 
 type optionState uint8
 
@@ -36,7 +38,9 @@ func (me optionState) String() string {
 // This specifies how many value *must* be present—if the option is given at
 // all. So even if the ValueCount is TwoValues, if the option isn't given
 // the option's Value will be empty. But if it _is_ given, then either it
-// will have exactly two values, or there will be a Parser error.
+// will have exactly two values, or there will be a Parser error. For other
+// counts set the option's MinValues and/or MaxValues (either of which, if
+// 0, is taken from the ValueCount).
 type ValueCount uint8
 
 const (
@@ -44,8 +48,26 @@ const (
 	TwoValues
 	ThreeValues
 	FourValues
+	ZeroOrMoreValues
 )
 
+// Returns the minimum and maximum counts (maximum may be Unlimited).
+func (me ValueCount) bounds() (int, int) {
+	switch me {
+	case OneOrMoreValues:
+		return 1, Unlimited
+	case TwoValues:
+		return 2, 2
+	case ThreeValues:
+		return 3, 3
+	case FourValues:
+		return 4, 4
+	case ZeroOrMoreValues:
+		return 0, Unlimited
+	}
+	panic("BUG: missing ValueCount case")
+}
+
 func (me ValueCount) String() string {
 	switch me {
 	case OneOrMoreValues:
@@ -56,12 +78,15 @@ func (me ValueCount) String() string {
 		return "three"
 	case FourValues:
 		return "four"
+	case ZeroOrMoreValues:
+		return "zero or more"
 	default:
 		return "BUG: invalid ValueCount"
 	}
 }
 
-// This specifies how many positionals *must* be present.
+// This specifies how many positionals *must* be present. For other
+// counts use [Parser.SetPositionalRange].
 type PositionalCount uint8
 
 const (
@@ -110,6 +135,90 @@ func (me PositionalCount) String() string {
 	}
 }
 
+// Returns the minimum and maximum counts (maximum may be Unlimited).
+func (me PositionalCount) bounds() (int, int) {
+	switch me {
+	case ZeroPositionals:
+		return 0, 0
+	case ZeroOrOnePositionals:
+		return 0, 1
+	case ZeroToTwoPositionals:
+		return 0, 2
+	case ZeroOrMorePositionals:
+		return 0, Unlimited
+	case OnePositional:
+		return 1, 1
+	case TwoPositionals:
+		return 2, 2
+	case TwoOrThreePositionals:
+		return 2, 3
+	case ThreePositionals:
+		return 3, 3
+	case FourPositionals:
+		return 4, 4
+	case OneOrTwoPositionals:
+		return 1, 2
+	case OneToThreePositionals:
+		return 1, 3
+	case OneOrMorePositionals:
+		return 1, Unlimited
+	}
+	panic("BUG: missing PositionalCount case")
+}
+
+// This specifies whether options may follow positionals. In every case,
+// -- (or -) means that all the arguments that follow are positionals.
+type ArgOrder uint8
+
+const (
+	// POSIX-style (the default): the first positional ends the options,
+	// so any that follow are positionals, e.g., myapp file1 -v file2 has
+	// three positionals.
+	POSIXOrder ArgOrder = iota
+	// GNU-style: options may be given anywhere, e.g., myapp file1 -v
+	// file2 is the same as myapp -v file1 file2.
+	GNUOrder
+	// As for GNUOrder, except that unrecognized options that follow a
+	// positional are positionals rather than errors, e.g., for wrappers
+	// that pass them on to another program.
+	PassUnknownOrder
+)
+
+// This specifies which transformations and checks are applied to the values
+// of [PathOption]s (and to positionals, see [Parser.PositionalKind]).
+// Combine them using |, e.g., PathExpand | PathMustBeFile | PathReadable.
+// The checks are not applied to "-" (which is used for stdin or stdout).
+type PathCheck uint16
+
+const (
+	PathExpand          PathCheck = 1 << iota // Expand ~ and $VARs.
+	PathAbsolute                              // Make the path absolute.
+	PathMustExist                             // The path must exist.
+	PathMustBeFile                            // The path must be a file.
+	PathMustBeDir                             // The path must be a folder.
+	PathReadable                              // The path must be readable.
+	PathWritable                              // The path must be writable.
+	PathParentMustExist                       // The path's folder must exist.
+)
+
+const NoPathChecks PathCheck = 0
+
+type helpLevel uint8
+
+const (
+	briefHelp helpLevel = iota // -h if Parser.BriefHelp is true
+	fullHelp                   // --help (and -h by default)
+	allHelp                    // --help-all
+)
+
+type defaultDisplay uint8
+
+const (
+	parserShowsDefault defaultDisplay = iota // as per Parser.ShowDefaults
+	alwaysShowDefault
+	neverShowDefault
+)
+
 type datum struct {
 	arg    string
 	lenArg int
@@ -128,5 +237,12 @@ const (
 	eWrongPositionalCount   // 108
 	eInvalidName            // 109
 	eEmptyPositionalVarName // 110
+	eInvalidBinding         // 111
+	eMutuallyExclusive      // 112
+	eInvalidHelpTemplate    // 113
+	eUnknownHelpTopic       // 114
+	eInvalidPositional      // 115
+	eInvalidCommand         // 116
+	eInvalidValueCount      // 117
 	eBug                    = 999
 )
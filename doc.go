@@ -124,7 +124,132 @@
 // # Hidden Options
 //
 // An option can be hidden by calling Hide on it. Such options work normally
-// but don't show up in -h or --help texts.
+// but don't show up in -h or --help texts (only in the --help-all text).
+//
+// For applications with many options, set [Parser.BriefHelp] to true so
+// that -h shows a brief summary and --help shows everything. Options that
+// most users don't need can be marked by calling Advanced on them: these
+// are shown by --help but not by a brief -h.
+//
+// For applications with long help texts, set [Parser.UsePager] to true so
+// that help that is too tall for the terminal is shown using a pager.
+//
+// # Defaults, Ranges, and Choices in the Help
+//
+// Set [Parser.ShowDefaults] to true to append each option's choices (for
+// [Parser.Choice]), range (for [Parser.IntInRange] and
+// [Parser.RealInRange]), and default to its help text, so that the help
+// never drifts from the actual values. The formats used can be changed
+// (e.g., [Parser.DefaultFormat]), and an option's own ShowDefault,
+// HideDefault, and SetDefaultText methods override the parser's setting.
+//
+//	parser := NewParser()
+//	parser.ShowDefaults = true
+//	parser.IntInRange("indent", "Indent", 0, 8, 2) // help: Indent [range: 0..8] [default: 2]
+//
+// # Option Groups
+//
+// For applications with many options, the options can be shown in groups
+// in the help, each under its own heading. Groups can also be used to make
+// options mutually exclusive.
+//
+//	parser := NewParser()
+//	input := parser.Group("Input", "Options for reading data.")
+//	input.Add(parser.Str("encoding", "The input encoding", "utf-8"))
+//	output := parser.Group("Output", "")
+//	output.Exclusive = true // at most one of --csv and --json
+//	output.Add(parser.Flag("csv", "Output CSV"),
+//		parser.Flag("json", "Output JSON"))
+//
+// # Help Templates
+//
+// The help's layout can be changed by setting [Parser.HelpTemplate] to a
+// [text/template] that is rendered against the [HelpModel] (which has the
+// app name, usage, descriptions, positionals, and options and groups). The
+// built-in layout is [DefaultHelpTemplate], which is a good starting point.
+//
+//	parser := NewParser()
+//	parser.HelpTemplate = `{{.AppName}} {{.Usage}}
+//	{{range .Options}}{{.LongName}}{{.ArgText}}: {{.Help}}
+//	{{end}}`
+//
+// # Examples in the Help
+//
+// Use [Parser.Example] to add examples that are shown in the help's
+// "examples:" section (before the EndDesc). To ensure that the examples
+// stay valid as the options change, call [CheckExamples] in a test.
+//
+//	parser.Example("-v --format=json data.txt", "Convert data.txt to JSON.")
+//
+// # Help Topics
+//
+// Giving --help=NAME shows the detailed help for the option with the given
+// long name: its long help (see each option's SetLongHelp method) or help,
+// choices, range, default, and any examples that use it. Extra topics can
+// be added with [Parser.HelpTopic], and --help=topics lists them. (To show
+// this help programmatically, use [Parser.OnTopicHelp].)
+//
+//	parser.HelpTopic("formats", "The supported formats are...")
+//
+// # Color and Themes
+//
+// The help and error messages are styled using the parser's [Theme]
+// (which can be changed) if the output is a terminal (checked separately
+// for stdout and stderr), unless the NO_COLOR, FORCE_COLOR, or TERM
+// environment variables say otherwise. Set [Parser.Color] to override
+// this, and set [Parser.ColorName] to let the user override it.
+//
+//	parser := NewParser()
+//	parser.Theme.Default = "\x1B[32m" // green
+//	parser.ColorName = "color"       // adds --color=auto|always|never
+//
+// # Output and Exiting
+//
+// By default the help and version are written to stdout, errors are
+// written to stderr, and the program is ended with os.Exit. Set a
+// Parser's Stdout, Stderr, and Exit to change this, e.g., for tests or
+// servers. If Exit returns, the parsing methods return an [*ExitError].
+//
+//	var out, errs strings.Builder
+//	parser := NewParser()
+//	parser.Stdout, parser.Stderr = &out, &errs
+//	parser.Exit = func(int) {}
+//	err := parser.ParseLine("--help") // err is an *ExitError with Code 0
+//
+// A Parser can parse many command lines (e.g., in a REPL): each parse
+// starts afresh, and [Parser.Reset] can be used to clear the results.
+//
+// For testing clip-based command line interfaces, see the cliptest
+// package, which runs a parser on arguments and captures its exit code and
+// output, and provides golden file helpers for the help.
+//
+// # Subcommands
+//
+// For git-style command lines (e.g., "myapp -v add -f x"), use
+// [Parser.Subcommand] to add each subcommand and its options to its own
+// [Parser]. The options before the command are the top-level Parser's and
+// those after it are the subcommand's; after parsing, [Parser.Command]
+// returns the command that was given.
+//
+//	parser := NewParser()
+//	verboseOpt := parser.Flag("verbose", "Show more output")
+//	add := parser.Subcommand("add", "Add items.")
+//	forceOpt := add.Flag("force", "Replace existing items")
+//	list := parser.Subcommand("list", "List items.")
+//	parser.ParseLine("-v add -f x") // parser.Command() == "add"
+//
+// # Interactive Shells
+//
+// A [Shell] reads lines (e.g., from stdin) and runs each one as a command
+// whose arguments are parsed by the command's own [Parser]. Errors are
+// shown but don't end the shell, and help, history, and exit are built in.
+// [Shell.Complete] provides completions for tab completion. The Shell's
+// Theme and Color are used for its own output and its commands' output.
+//
+//	shell := NewShell()
+//	add := shell.Command("add", "Add items.", addItems)
+//	add.Flag("force", "Replace existing items")
+//	err := shell.Run()
 //
 // # Validators
 //
@@ -152,11 +277,79 @@
 //		return "", fmt.Sprintf("invalid format: %q", value)
 //	}
 //
+// # Options of Any Type
+//
+// For options of other types (e.g., IP addresses, URLs, enums, colors), use
+// [Var] or [Vars] (for multi-value options) with a function that parses a
+// string into the type or returns an error. (These are functions rather
+// than [Parser] methods since Go doesn't support generic methods.)
+//
+//	parser := NewParser()
+//	urlOpt := Var(&parser, "url", "The URL to fetch", &url.URL{}, url.Parse)
+//	portsOpt := Vars(&parser, "port", "The ports to try", strconv.Atoi)
+//	parser.ParseLine("-u https://example.com -p 80 8080")
+//	u := urlOpt.Value() // u is a *url.URL
+//	ports := portsOpt.Value() // ports == []int{80, 8080}
+//
+// For types that implement [encoding.TextUnmarshaler] (e.g., netip.Addr,
+// big.Int), use [Parser.Text] or [Texts] which unmarshal the given values
+// directly into the given variable and show its default in the help (if
+// the type implements [encoding.TextMarshaler]).
+//
+//	parser := NewParser()
+//	addr := netip.MustParseAddr("127.0.0.1")
+//	parser.Text("addr", "The address to listen on", &addr)
+//	parser.ParseLine("-a 192.168.1.7") // addr is now 192.168.1.7
+//
+// # Struct Binding
+//
+// Rather than creating options one by one and copying their values into a
+// config struct, use [Bind] to create the options from the struct's fields
+// (using struct tags for names, help, defaults, etc.); the parsed values
+// are written back into the struct after a successful parse.
+//
+//	type config struct {
+//		Count   int    `clip:"count,c" help:"How many" default:"2" range:"0,100"`
+//		Format  string `help:"Output format" default:"csv" choices:"csv,json"`
+//		Verbose bool   `help:"Show more output"`
+//	}
+//	parser := NewParser()
+//	var cfg config
+//	if err := Bind(&parser, &cfg); err != nil {
+//		panic(err)
+//	}
+//	parser.ParseLine("-c5 -v") // cfg.Count == 5 && cfg.Verbose
+//
+// A struct field with a cmd tag (e.g., `cmd:"add" help:"Add items."`)
+// becomes a subcommand whose options are created from its own fields.
+//
+// Alternatively, use the Var-style methods (e.g., [Parser.IntVar],
+// [Parser.StrVar], [Parser.FlagVar], [Parser.StrsVar]) which set the
+// variables they're given after a successful parse. There's one for every
+// option type except those made by [Parser.Text] and [Texts] (which write
+// into the caller's values anyway) and by the generic [Var] and [Vars].
+//
+//	parser := NewParser()
+//	var count int
+//	var verbose bool
+//	parser.IntVar(&count, "count", "How many", 2)
+//	parser.FlagVar(&verbose, "verbose", "Show more output")
+//	parser.ParseLine("-c5") // count == 5 && !verbose
+//
 // # Mutli-Value Options
 //
 // For ints, reals, and strings it is possible to set multi-value options,
 // that is options that accept one or more. See [Parser.Ints],
-// [Parser.Reals], and [Parser.Strs].
+// [Parser.Reals], and [Parser.Strs]. How many values are wanted is set by
+// the option's ValueCount (see [ValueCount]), or for other counts, by its
+// MinValues and/or MaxValues (an impossible range, e.g., 3 to 2, is a
+// Parser error).
+//
+//	parser := NewParser()
+//	includeOpt := parser.Strs("include", "Folders to include")
+//	includeOpt.MinValues = 2
+//	includeOpt.MaxValues = 5 // or Unlimited
+//	// usage: -i, --include <INCLUDE1> <INCLUDE2> [INCLUDE3 [INCLUDE4 [INCLUDE5]]]
 //
 // It is often more convenient to use a single-value option with multiple
 // values comma separated. For example:
@@ -164,6 +357,108 @@
 //	--pages 21,36,42,43
 //	-f csv,json,xml
 //
+// For lists of ints and int ranges (e.g., page or CPU numbers), use
+// [Parser.IntRanges] or [Parser.IntRangesInRange]. For example:
+//
+//	parser := NewParser()
+//	pagesOpt := parser.IntRangesInRange("pages", "Pages to print", 1, 20)
+//	parser.ParseLine("-p 1-3,8,15-")
+//	pages, ranges := pagesOpt.Value()
+//	// pages == []int{1, 2, 3, 8, 15, 16, 17, 18, 19, 20}
+//	// ranges == []IntRange{{1, 3}, {8, 8}, {15, 20}}
+//
+// # Named Positionals
+//
+// By default positionals are collected in the Parser's Positionals. How
+// many are wanted is set by the Parser's PositionalCount, or for other
+// counts, by [Parser.SetPositionalRange].
+//
+//	parser := NewParser()
+//	parser.SetPositionalRange(2, 6) // two to six
+//	parser.SetPositionalRange(3, Unlimited) // at least three
+//
+// Alternatively,
+// named positionals can be declared in order using [Parser.Positional],
+// [Parser.PositionalInt], and [Parser.PositionalRest], each with its own
+// help, and which may be optional. These determine how many positionals
+// are wanted, and are shown in the usage.
+//
+//	parser := NewParser()
+//	srcPos := parser.Positional("SRC", "The file to copy")
+//	dstPos := parser.Positional("DST", "Where to copy it")
+//	patternsPos := parser.PositionalRest("PATTERN", "Lines to copy")
+//	parser.ParseLine("in.txt out.txt ^a ^b") // usage: <SRC> <DST> [PATTERN ...]
+//	src := srcPos.Value() // src == "in.txt"
+//	patterns := patternsPos.Value() // patterns == []string{"^a", "^b"}
+//
+// # Argument Order
+//
+// By default parsing is POSIX-style: the first positional ends the
+// options, so any arguments that follow it are positionals, e.g.,
+// `myapp -v ls -l` has the positionals ls and -l. To allow options to be
+// given before, after, or between positionals (until -- is given), so
+// that `myapp file1 -v file2` is the same as `myapp -v file1 file2`, set
+// the Parser's ArgOrder to [GNUOrder] (unless the POSIXLY_CORRECT
+// environment variable is set). For wrappers that pass options on to
+// another program, use [PassUnknownOrder] so that unrecognized options
+// that follow a positional are positionals.
+//
+//	parser := NewParser()
+//	parser.ArgOrder = GNUOrder
+//	verboseOpt := parser.Flag("verbose", "whether to show more output")
+//	parser.ParseLine("file1 -v file2") // parser.Positionals == []string{"file1", "file2"}
+//
+// Alternatively, set the Parser's CollectUnknown to true, and any
+// unrecognized options (with their values if attached) are put in the
+// Parser's Unknown, wherever they're given.
+//
+//	parser := NewParser()
+//	parser.CollectUnknown = true
+//	parser.ParseLine("--jobs=4 -Wall file1") // parser.Unknown == []string{"--jobs=4", "-Wall"}
+//
+// # Paths
+//
+// For options that accept a file or folder path, use [Parser.Path] and set
+// the option's Checks (see [PathCheck]). The same checks can be applied to
+// positionals by setting [Parser.PositionalKind].
+//
+//	parser := NewParser()
+//	configOpt := parser.Path("config", "The config file", "~/.myapp.ini")
+//	configOpt.Checks = PathExpand | PathMustBeFile | PathReadable
+//	parser.PositionalKind = PathExpand | PathAbsolute | PathMustExist
+//	parser.ParseLine("-c ~/myapp.ini one.txt two.txt")
+//	config := configOpt.Value() // e.g., /home/user/myapp.ini
+//
+// # Files
+//
+// For options that accept a file to read from or write to, use
+// [Parser.InputFile] or [Parser.OutputFile]. Their values are opened
+// lazily, "-" means stdin or stdout, files with a .gz suffix are
+// transparently (de)compressed, and by default output files are written
+// atomically (i.e., to a temporary file which replaces the output file
+// when closed). For positionals use [Parser.PositionalReader] and
+// [Parser.PositionalWriter].
+//
+//	parser := NewParser()
+//	infileOpt := parser.InputFile("infile", "The file to read", "-")
+//	outfileOpt := parser.OutputFile("outfile", "The file to write", "-")
+//	parser.ParseLine("-i data.csv.gz -o -")
+//	reader := infileOpt.Value() // reads decompressed data.csv.gz
+//	defer reader.Close()
+//	writer := outfileOpt.Value() // writes to stdout
+//	defer writer.Close()
+//
+// # Standard Library Flags
+//
+// To use clip's parsing and help for a tool that is built on a
+// [flag.FlagSet], use [FromFlagSet]; and for libraries that expect a
+// [flag.FlagSet], use [Parser.ToFlagSet].
+//
+//	fs := flag.NewFlagSet("myapp", flag.ExitOnError)
+//	verbose := fs.Bool("verbose", false, "Show more output")
+//	parser := FromFlagSet(fs)
+//	parser.Parse() // *verbose is set as normal
+//
 // # Post-Parsing Validation
 //
 // If some post-parsing validation finds invalid data it is possible to
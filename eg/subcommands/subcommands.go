@@ -77,6 +77,8 @@ func parseFormat(appName string, args []string, desc string) config {
 	parser.LongDesc = desc
 	parser.PositionalHelp = "The required infile and the required " +
 		"outfile; use - to write to stdout or = to overwrite infile"
+	parser.ShowDefaults = true
+	parser.RangeFormat = "" // The ranges are explained in the help texts
 	lintOpt := parser.Flag("lint",
 		"Print lints to stderr. If only lints are wanted use the l or "+
 			"lint subcommand")
@@ -91,14 +93,14 @@ func parseFormat(appName string, args []string, desc string) config {
 			"-s|--standalone)")
 	indentOpt := parser.IntInRange("indent",
 		"Indent (0-8 spaces or 9 to use a tab; ignored if -c|--compact "+
-			"used) [default: 2]", 0, 9, 2)
+			"used)", 0, 9, 2)
 	wrapWidthOpt := parser.IntInRange("wrapwidth",
-		"Wrapwidth (40-240; ignored if -c|--compact used) [default: 96]",
+		"Wrapwidth (40-240; ignored if -c|--compact used)",
 		40, 240, 96)
 	decimalsOpt := parser.IntInRange("decimals",
 		"Decimal digits (0-15; 0 means use at least one (even if .0) "+
 			"and as many as needed; 1-15 means used that fixed number of "+
-			"digits) [default: 0]", 0, 15, 0)
+			"digits)", 0, 15, 0)
 	compactOpt := parser.Flag("compact",
 		"Use compact output format (not human friendly; ignores indent "+
 			"and wrapwidth)")
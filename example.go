@@ -0,0 +1,63 @@
+// Copyright © 2022 Mark Summerfield. All rights reserved.
+// License: Apache-2.0
+
+package clip
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+type example struct {
+	cmdline     string
+	explanation string
+}
+
+// Example adds an example of the application's use that is shown in the
+// help's "examples:" section. The cmdline is the command line's arguments
+// (without the application's name) and the explanation says what it does.
+// Use [CheckExamples] in a test to ensure that the examples stay valid.
+//
+//	parser.Example("-v --format=csv data.txt", "Convert data.txt to CSV.")
+func (me *Parser) Example(cmdline, explanation string) {
+	me.examples = append(me.examples, example{cmdline, explanation})
+}
+
+// CheckExamples returns nil if every example added with [Parser.Example]
+// is valid; otherwise it returns an error for the first invalid example.
+// Each example is parsed using a new Parser created by calling newParser
+// (so that examples can't affect each other). (Examples that show the help or
+// version are valid.) CheckExamples is intended for tests.
+//
+//	func newParser() clip.Parser { ... }
+//
+//	func TestExamples(t *testing.T) {
+//		if err := clip.CheckExamples(newParser); err != nil {
+//			t.Error(err)
+//		}
+//	}
+func CheckExamples(newParser func() Parser) error {
+	parser := newParser()
+	for _, example := range parser.examples {
+		if msg := checkExample(newParser, example.cmdline); msg != "" {
+			return fmt.Errorf("invalid example %q: %s", example.cmdline,
+				msg)
+		}
+	}
+	return nil
+}
+
+// Returns "" if the cmdline is valid; otherwise an error message.
+func checkExample(newParser func() Parser, cmdline string) string {
+	parser := newParser()
+	parser.Stdout = io.Discard
+	parser.Stderr = io.Discard
+	parser.Exit = func(int) {} // so that ParseLine returns an *ExitError
+	err := parser.ParseLine(cmdline)
+	var exitErr *ExitError
+	if err == nil || (errors.As(err, &exitErr) && exitErr.Code == 0) {
+		return ""
+	}
+	return err.Error()
+}
@@ -0,0 +1,326 @@
+// Copyright © 2022 Mark Summerfield. All rights reserved.
+// License: Apache-2.0
+
+package clip
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// InputFileOption is an option for accepting a file to read from. Its
+// Value is opened lazily (i.e., when first read), "-" means stdin, and
+// files with a .gz suffix are transparently decompressed.
+type InputFileOption struct {
+	*commonOption
+	TheDefault    string // The options default value, e.g., "-" for stdin.
+	AllowImplicit bool   // If true, giving the option with no value means use the default.
+	value         string
+	reader        *fileReader
+}
+
+// Always returns a *InputFileOption; _and_ either nil or error.
+func newInputFileOption(name, help, theDefault string) (*InputFileOption,
+	error) {
+	err := checkName(name, "option")
+	shortName, longName := namesForName(name)
+	return &InputFileOption{commonOption: &commonOption{longName: longName,
+		shortName: shortName, help: help, state: notGiven},
+		TheDefault: theDefault}, err
+}
+
+// Filename returns the given filename or if the option wasn't given, the
+// default filename.
+func (me *InputFileOption) Filename() string {
+	if me.state == hadValue {
+		return me.value
+	}
+	return me.TheDefault
+}
+
+// Value returns a reader for the given file (or for the default file if
+// the option wasn't given), or nil if there is no filename. The reader
+// must be closed by the caller.
+func (me *InputFileOption) Value() io.ReadCloser {
+	filename := me.Filename()
+	if filename == "" {
+		return nil
+	}
+	if me.reader == nil || me.reader.filename != filename {
+		me.reader = &fileReader{filename: filename}
+	}
+	return me.reader
+}
+
+func (me *InputFileOption) wantsValue() bool {
+	return me.state == given
+}
+
+func (me *InputFileOption) check() string {
+	if me.state == given && !me.AllowImplicit {
+		return "expected exactly one value for " + me.LongName() +
+			", got none"
+	}
+	return ""
+}
+
+func (me *InputFileOption) defaultText() string {
+	return me.TheDefault
+}
+
+func (me *InputFileOption) addValue(value string) string {
+	if value == "" {
+		return "option " + me.longName + " expected a nonempty filename"
+	}
+	me.value = value
+	me.state = hadValue
+	return ""
+}
+
+func (me *InputFileOption) reset() {
+	me.commonOption.reset()
+	me.value = ""
+	me.reader = nil // the caller is responsible for closing it
+}
+
+// OutputFileOption is an option for accepting a file to write to. Its
+// Value is opened lazily (i.e., when first written to or closed), "-"
+// means stdout, and files with a .gz suffix are transparently compressed.
+type OutputFileOption struct {
+	*commonOption
+	TheDefault    string // The options default value, e.g., "-" for stdout.
+	AllowImplicit bool   // If true, giving the option with no value means use the default.
+
+	// If true (the default), write to a temporary file that replaces the
+	// file when closed. (A file that exists but isn't a regular file,
+	// e.g., a FIFO, device, or symlink, is always written directly.)
+	Atomic bool
+
+	value  string
+	writer *fileWriter
+}
+
+// Always returns a *OutputFileOption; _and_ either nil or error.
+func newOutputFileOption(name, help, theDefault string) (*OutputFileOption,
+	error) {
+	err := checkName(name, "option")
+	shortName, longName := namesForName(name)
+	return &OutputFileOption{commonOption: &commonOption{
+		longName: longName, shortName: shortName, help: help,
+		state: notGiven}, TheDefault: theDefault, Atomic: true}, err
+}
+
+// Filename returns the given filename or if the option wasn't given, the
+// default filename.
+func (me *OutputFileOption) Filename() string {
+	if me.state == hadValue {
+		return me.value
+	}
+	return me.TheDefault
+}
+
+// Value returns a writer for the given file (or for the default file if
+// the option wasn't given), or nil if there is no filename. The writer
+// must be closed by the caller: if the option is Atomic, this is when the
+// file is actually created or replaced.
+func (me *OutputFileOption) Value() io.WriteCloser {
+	filename := me.Filename()
+	if filename == "" {
+		return nil
+	}
+	if me.writer == nil || me.writer.filename != filename {
+		me.writer = &fileWriter{filename: filename, atomic: me.Atomic}
+	}
+	return me.writer
+}
+
+func (me *OutputFileOption) wantsValue() bool {
+	return me.state == given
+}
+
+func (me *OutputFileOption) check() string {
+	if me.state == given && !me.AllowImplicit {
+		return "expected exactly one value for " + me.LongName() +
+			", got none"
+	}
+	return ""
+}
+
+func (me *OutputFileOption) defaultText() string {
+	return me.TheDefault
+}
+
+func (me *OutputFileOption) addValue(value string) string {
+	if value == "" {
+		return "option " + me.longName + " expected a nonempty filename"
+	}
+	me.value = value
+	me.state = hadValue
+	return ""
+}
+
+func (me *OutputFileOption) reset() {
+	me.commonOption.reset()
+	me.value = ""
+	me.writer = nil // the caller is responsible for closing it
+}
+
+type fileReader struct {
+	filename string
+	file     *os.File
+	reader   io.Reader
+	err      error
+	opened   bool
+}
+
+func (me *fileReader) open() {
+	me.opened = true
+	if me.filename == "-" {
+		me.reader = os.Stdin
+		return
+	}
+	if me.file, me.err = os.Open(me.filename); me.err != nil {
+		return
+	}
+	me.reader = me.file
+	if isGzipped(me.filename) {
+		gz, err := gzip.NewReader(me.file)
+		if err != nil {
+			me.reader = nil
+			me.err = err
+			return
+		}
+		me.reader = gz
+	}
+}
+
+func (me *fileReader) Read(data []byte) (int, error) {
+	if !me.opened {
+		me.open()
+	}
+	if me.err != nil {
+		return 0, me.err
+	}
+	if me.reader == nil { // closed
+		return 0, os.ErrClosed
+	}
+	return me.reader.Read(data)
+}
+
+// Close closes the file (if it was opened); stdin is never closed.
+func (me *fileReader) Close() error {
+	var err error
+	if gz, ok := me.reader.(*gzip.Reader); ok && gz != nil {
+		err = gz.Close()
+	}
+	if me.file != nil {
+		if e := me.file.Close(); err == nil {
+			err = e
+		}
+		me.file = nil
+	}
+	me.reader = nil
+	return err
+}
+
+type fileWriter struct {
+	filename string
+	atomic   bool
+	file     *os.File
+	gz       *gzip.Writer
+	writer   io.Writer
+	err      error
+	opened   bool
+}
+
+func (me *fileWriter) open() {
+	me.opened = true
+	if me.filename == "-" {
+		me.writer = os.Stdout
+		return
+	}
+	if info, err := os.Lstat(me.filename); err == nil &&
+		!info.Mode().IsRegular() {
+		me.atomic = false // e.g., a FIFO, device, or symlink
+	}
+	if me.atomic {
+		me.file, me.err = os.CreateTemp(filepath.Dir(me.filename),
+			"."+filepath.Base(me.filename)+".*")
+	} else { // write-only so that, e.g., a FIFO waits for its reader
+		me.file, me.err = os.OpenFile(me.filename,
+			os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0o666)
+	}
+	if me.err != nil {
+		return
+	}
+	me.writer = me.file
+	if isGzipped(me.filename) {
+		me.gz = gzip.NewWriter(me.file)
+		me.writer = me.gz
+	}
+}
+
+func (me *fileWriter) Write(data []byte) (int, error) {
+	if !me.opened {
+		me.open()
+	}
+	if me.err != nil {
+		return 0, me.err
+	}
+	n, err := me.writer.Write(data)
+	if err != nil {
+		me.err = err
+	}
+	return n, err
+}
+
+// Close flushes and closes the file (creating it if nothing was written);
+// stdout is never closed. For atomic writers the temporary file replaces
+// the target file—unless there was an error, in which case the temporary
+// file is removed and the target file is left untouched.
+func (me *fileWriter) Close() error {
+	if !me.opened {
+		me.open()
+	}
+	if me.file == nil {
+		return me.err
+	}
+	err := me.err
+	if me.gz != nil {
+		if e := me.gz.Close(); err == nil {
+			err = e
+		}
+	}
+	if e := me.file.Close(); err == nil {
+		err = e
+	}
+	tempName := me.file.Name()
+	me.file = nil
+	if me.atomic {
+		if err == nil {
+			err = replaceFile(tempName, me.filename)
+		}
+		if err != nil {
+			os.Remove(tempName)
+		}
+	}
+	return err
+}
+
+func replaceFile(tempName, filename string) error {
+	var mode os.FileMode = 0o644
+	if info, err := os.Stat(filename); err == nil {
+		mode = info.Mode().Perm()
+	}
+	if err := os.Chmod(tempName, mode); err != nil {
+		return err
+	}
+	return os.Rename(tempName, filename)
+}
+
+func isGzipped(filename string) bool {
+	return strings.HasSuffix(strings.ToLower(filename), ".gz")
+}
@@ -0,0 +1,209 @@
+// Copyright © 2022 Mark Summerfield. All rights reserved.
+// License: Apache-2.0
+
+package clip
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strconv"
+	"time"
+	"unicode/utf8"
+)
+
+// FromFlagSet returns a new [Parser] with an option for every flag in the
+// given [flag.FlagSet]: bool flags become [FlagOption]s (plus a --no-name
+// FlagOption for those that default to true, so that they can be turned
+// off), int flags become [IntOption]s, and all other flags become
+// [VarOption]s. After a successful
+// parse each given option's value is set on its flag, so code that reads
+// the flags' variables works unchanged. (The positionals are in the
+// Parser's Positionals, not in the flag set's Args.) Values of the flag
+// package's own types are validated while parsing, but values of other
+// flag.Value types can only be validated by setting them, so if one of
+// those is invalid, the flags set before it keep their new values.
+//
+// Single-rune flag names become short names (e.g., -v); longer names
+// become long names with no short name (e.g., --verbose, not -verbose). A
+// "help" flag is ignored since clip provides its own.
+//
+//	fs := flag.NewFlagSet("myapp", flag.ExitOnError)
+//	verbose := fs.Bool("verbose", false, "Show more output")
+//	parser := FromFlagSet(fs)
+//	parser.ParseLine("--verbose") // *verbose == true
+func FromFlagSet(fs *flag.FlagSet) *Parser {
+	parser := NewParserUser(fs.Name(), "")
+	fs.VisitAll(func(f *flag.Flag) {
+		if f.Name == parser.HelpName {
+			return
+		}
+		option := importFlag(&parser, f)
+		if utf8.RuneCountInString(f.Name) > 1 {
+			option.SetShortName(NoShortName)
+		}
+	})
+	return &parser
+}
+
+func importFlag(parser *Parser, f *flag.Flag) optioner {
+	if isBoolFlag(f.Value) {
+		return importBoolFlag(parser, f)
+	}
+	if getter, ok := f.Value.(flag.Getter); ok {
+		if theDefault, ok := getter.Get().(int); ok {
+			option := parser.Int(f.Name, f.Usage, theDefault)
+			parser.afterParse = append(parser.afterParse, func() {
+				if option.Given() {
+					_ = f.Value.Set(strconv.Itoa(option.Value()))
+				}
+			})
+			return option
+		}
+	}
+	option := Var(parser, f.Name, f.Usage, f.DefValue,
+		func(value string) (string, error) {
+			return value, checkFlagValue(f, value)
+		})
+	parser.flagSetters = append(parser.flagSetters, func() string {
+		if option.Given() {
+			if err := f.Value.Set(option.Value()); err != nil {
+				return invalidVarValue(option.LongName(), option.Value(),
+					err)
+			}
+		}
+		return ""
+	})
+	return option
+}
+
+func importBoolFlag(parser *Parser, f *flag.Flag) optioner {
+	option := parser.Flag(f.Name, f.Usage)
+	var noOption *FlagOption
+	if theDefault, err := strconv.ParseBool(f.DefValue); err == nil &&
+		theDefault {
+		noOption = parser.Flag("no-"+f.Name, "Turn off --"+f.Name+".")
+		noOption.SetShortName(NoShortName)
+	}
+	parser.flagSetters = append(parser.flagSetters, func() string {
+		if noOption != nil && noOption.Given() {
+			if option.Given() {
+				return fmt.Sprintf("options --%s and --%s are mutually "+
+					"exclusive", option.LongName(), noOption.LongName())
+			}
+			_ = f.Value.Set("false")
+		} else if option.Given() {
+			_ = f.Value.Set("true")
+		}
+		return ""
+	})
+	return option
+}
+
+// Returns an error if the value is invalid for the flag's type (for the
+// flag package's standard types; other types are validated when set).
+func checkFlagValue(f *flag.Flag, value string) error {
+	getter, ok := f.Value.(flag.Getter)
+	if !ok {
+		return nil
+	}
+	var err error
+	switch getter.Get().(type) {
+	case int64:
+		_, err = strconv.ParseInt(value, 0, 64)
+	case uint:
+		_, err = strconv.ParseUint(value, 0, strconv.IntSize)
+	case uint64:
+		_, err = strconv.ParseUint(value, 0, 64)
+	case float64:
+		_, err = strconv.ParseFloat(value, 64)
+	case time.Duration:
+		_, err = time.ParseDuration(value)
+	}
+	return err
+}
+
+func isBoolFlag(value flag.Value) bool {
+	if boolFlag, ok := value.(interface{ IsBoolFlag() bool }); ok {
+		return boolFlag.IsBoolFlag()
+	}
+	return false
+}
+
+// ToFlagSet returns a new [flag.FlagSet] (using [flag.ContinueOnError])
+// with a flag for each of the Parser's options (and another for each short
+// name), for use with libraries that expect a flag set. Setting a flag sets
+// its option's value just as parsing would.
+func (me *Parser) ToFlagSet() *flag.FlagSet {
+	fs := flag.NewFlagSet(me.appName, flag.ContinueOnError)
+	for _, option := range me.options {
+		value := &flagValue{option: option}
+		fs.Var(value, option.LongName(), option.Help())
+		if shortName := string(option.ShortName()); option.ShortName() !=
+			NoShortName && fs.Lookup(shortName) == nil {
+			fs.Var(value, shortName, option.Help())
+		}
+	}
+	return fs
+}
+
+// flagValue adapts an option to the flag.Value interface.
+type flagValue struct {
+	option optioner
+}
+
+func (me *flagValue) String() string {
+	switch option := me.option.(type) {
+	case nil: // The flag package may use a zero flagValue
+		return ""
+	case *FlagOption:
+		return strconv.FormatBool(option.Value())
+	case *IntOption:
+		return strconv.Itoa(option.Value())
+	case *RealOption:
+		return strconv.FormatFloat(option.Value(), 'g', -1, 64)
+	case *StrOption:
+		return option.Value()
+	case *PathOption:
+		return option.Value()
+	case *StrsOption:
+		return fmt.Sprint(option.Value())
+	case *IntsOption:
+		return fmt.Sprint(option.Value())
+	case *RealsOption:
+		return fmt.Sprint(option.Value())
+	case *IntRangesOption:
+		_, ranges := option.Value()
+		return fmt.Sprint(ranges)
+	case *InputFileOption:
+		return option.Filename()
+	case *OutputFileOption:
+		return option.Filename()
+	case *TextOption:
+		return fmt.Sprint(option.Value())
+	case valueTexter: // e.g., VarOption and VarsOption
+		return option.valueText()
+	}
+	return ""
+}
+
+func (me *flagValue) Set(value string) error {
+	me.option.setGiven()
+	if option, ok := me.option.(*FlagOption); ok {
+		given, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		option.value = given
+		return nil
+	}
+	if msg := me.option.addValue(value); msg != "" {
+		return errors.New(msg)
+	}
+	return nil
+}
+
+func (me *flagValue) IsBoolFlag() bool {
+	_, ok := me.option.(*FlagOption)
+	return ok
+}
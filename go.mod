@@ -8,9 +8,7 @@ require (
 	github.com/kopoli/go-terminal-size v0.0.0-20170219200355-5c97524c8b54
 	github.com/mark-summerfield/set v1.0.0
 	github.com/mark-summerfield/uterm v0.0.0-20250527071918-31945da2afaa
+	golang.org/x/sys v0.33.0
 )
 
-require (
-	github.com/mattn/go-isatty v0.0.20 // indirect
-	golang.org/x/sys v0.33.0 // indirect
-)
+require github.com/mattn/go-isatty v0.0.20 // indirect
@@ -0,0 +1,41 @@
+// Copyright © 2022 Mark Summerfield. All rights reserved.
+// License: Apache-2.0
+
+package clip
+
+import "strings"
+
+// OptionGroup is a group of options that are shown under their own heading
+// in the help. See [Parser.Group].
+type OptionGroup struct {
+	Title     string // The group's heading in the help, e.g., "Input".
+	Desc      string // Text shown between the heading and the options.
+	Exclusive bool   // If true, at most one of the group's options may be given.
+}
+
+// Add adds the given options to the group (moving them out of any other
+// group they are in) and returns the group.
+func (me *OptionGroup) Add(options ...optioner) *OptionGroup {
+	for _, option := range options {
+		option.setOptionGroup(me)
+	}
+	return me
+}
+
+// Returns "" if the group's options are valid; otherwise an error message.
+func (me *OptionGroup) check(options []optioner) string {
+	if !me.Exclusive {
+		return ""
+	}
+	names := make([]string, 0, len(options))
+	for _, option := range options {
+		if option.Given() {
+			names = append(names, "--"+option.LongName())
+		}
+	}
+	if len(names) > 1 {
+		return "options " + strings.Join(names, " and ") +
+			" are mutually exclusive"
+	}
+	return ""
+}
@@ -0,0 +1,382 @@
+// Copyright © 2022 Mark Summerfield. All rights reserved.
+// License: Apache-2.0
+
+package clip
+
+import (
+	"slices"
+	"strings"
+	"text/template"
+	"unicode/utf8"
+
+	"github.com/mark-summerfield/uterm"
+)
+
+// DefaultHelpTemplate is the [text/template] used to render the help when
+// the Parser's HelpTemplate is "". It is a useful starting point for custom
+// templates.
+//
+// Templates are rendered against a [HelpModel] and may use these functions
+// (as well as the standard ones):
+//
+//   - wrap TEXT: TEXT wrapped to the terminal's width (see [uterm.Wrapped])
+//   - indent TEXT: TEXT wrapped and indented like a group's description
+//   - column ARG HELP: ARG and HELP in two columns (as for positionals)
+//   - positionals ARGS: the []PositionalArgHelp in two aligned columns
+//   - options OPTIONS: the []OptionHelp in two aligned columns
+//   - example EXAMPLE: an [ExampleHelp]'s command line and explanation
+//   - strong TEXT: TEXT styled using the [Theme]'s OptionName
+//   - emph TEXT: TEXT styled using the [Theme]'s Heading
+//   - hint TEXT: TEXT underlined (see [Hint])
+//
+// The options function aligns all the model's options together unless the
+// Parser's AlignGroupsSeparately is true. One trailing newline (if any) is
+// dropped from the rendered help.
+const DefaultHelpTemplate = `{{if .ShortDesc}}{{wrap .ShortDesc}}
+
+{{end}}{{emph "usage:"}} {{strong .AppName}} {{.Usage}}
+{{if or .LongDesc .Positionals}}
+{{if .LongDesc}}{{wrap .LongDesc}}
+{{end}}{{if .Positionals}}
+{{emph "positional arguments:"}}
+{{if .PositionalArgs}}{{positionals .PositionalArgs}}{{else}}{{column .Positionals .PositionalHelp}}{{end}}{{end}}{{end}}{{if .Commands}}
+{{emph "commands:"}}
+{{commands .Commands}}{{end}}
+{{emph "optional arguments:"}}
+{{options .Options}}{{range .Groups}}
+{{emph .Title}}
+{{if .Desc}}{{indent .Desc}}
+{{end}}{{options .Options}}{{end}}{{if .Examples}}
+{{emph "examples:"}}
+{{range .Examples}}{{example .}}{{end}}{{end}}{{if .EndDesc}}
+{{wrap .EndDesc}}
+{{end}}{{if .Brief}}
+for more help run: {{.AppName}} {{.HelpName}}
+{{end}}`
+
+// HelpModel is the data that the help is rendered from: see
+// [Parser.HelpModel] and [DefaultHelpTemplate]. (A custom template can use
+// Omitted to say to use --help-all; the default template doesn't.)
+type HelpModel struct {
+	AppName        string
+	Usage          string // e.g., "[OPTIONS] [FILE1 [FILE2 ...]]"
+	ShortDesc      string
+	LongDesc       string
+	EndDesc        string
+	Positionals    string // e.g., "[FILE1 [FILE2 ...]]"; "" if there are none
+	PositionalHelp string
+	PositionalArgs []PositionalArgHelp // The named positionals (if any).
+	Commands       []CommandHelp       // The subcommands (if any).
+	Options        []OptionHelp        // The ungrouped options (and -h, --help).
+	Groups         []GroupHelp         // The groups that have any shown options.
+	Examples       []ExampleHelp
+	Width          int    // The width to wrap to.
+	Brief          bool   // True for brief help (see [Parser.BriefHelp]).
+	HelpName       string // e.g., "--help"
+	Omitted        bool   // True if some options aren't shown.
+}
+
+// OptionHelp is the help data for one (non-hidden) option.
+type OptionHelp struct {
+	ShortName string // e.g., "-v"; "" if the option has no short name
+	LongName  string // e.g., "--verbose"
+	ArgText   string // e.g., " [VERBOSE]"; "" for flags
+	Help      string // With choices, range, and default, if shown
+	LongHelp  string // Shown for --help=NAME; "" if it has none
+	Default   string // The default's text; "" if it has none
+}
+
+// PositionalArgHelp is the help data for one named positional: see
+// [Parser.Positional].
+type PositionalArgHelp struct {
+	Name string // e.g., "SRC"
+	Help string
+}
+
+// CommandHelp is the help data for one subcommand: see
+// [Parser.Subcommand].
+type CommandHelp struct {
+	Name string // e.g., "add"
+	Help string
+}
+
+// GroupHelp is the help data for one [OptionGroup].
+type GroupHelp struct {
+	Title     string // Always ends with a colon.
+	Desc      string
+	Exclusive bool
+	Options   []OptionHelp
+}
+
+// ExampleHelp is the help data for one example: see [Parser.Example].
+type ExampleHelp struct {
+	CmdLine     string // Including the app name, e.g., "myapp -v FILE"
+	Explanation string
+}
+
+// HelpModel returns the data that the help is rendered from, i.e., for
+// --help.
+func (me *Parser) HelpModel() HelpModel {
+	return me.helpModel(fullHelp)
+}
+
+func (me *Parser) helpModel(level helpLevel) HelpModel {
+	model := HelpModel{AppName: me.appName, Usage: "[OPTIONS]",
+		ShortDesc: me.ShortDesc, LongDesc: me.LongDesc,
+		EndDesc: me.EndDesc, PositionalHelp: me.PositionalHelp,
+		Width: me.width, Brief: level == briefHelp,
+		Omitted: slices.ContainsFunc(me.options, func(option optioner) bool {
+			return isOmitted(option, level)
+		}),
+		HelpName: "--" + me.HelpName}
+	if len(me.subcommands) > 0 {
+		model.Usage += " <COMMAND> ..."
+		for _, sub := range me.subcommands {
+			model.Commands = append(model.Commands,
+				CommandHelp{Name: sub.name, Help: sub.help})
+		}
+	} else if len(me.positionals) > 0 {
+		model.Positionals = me.namedPositionalsText()
+		model.Usage += " " + model.Positionals
+		for _, positional := range me.positionals {
+			model.PositionalArgs = append(model.PositionalArgs,
+				PositionalArgHelp{Name: positional.Name(),
+					Help: positional.Help()})
+		}
+	} else if minimum, maximum := me.positionalBounds(); maximum != 0 {
+		model.Positionals = positionalRangeText(minimum, maximum,
+			me.positionalVarName1, me.positionalVarNameN)
+		model.Usage += " " + model.Positionals
+	}
+	model.Options = append(me.optionsHelp(nil, level), OptionHelp{
+		ShortName: "-h", LongName: "--" + me.HelpName,
+		Help: "Show help and quit."})
+	for _, group := range me.groups {
+		if options := me.optionsHelp(group, level); len(options) > 0 {
+			title := group.Title
+			if !strings.HasSuffix(title, ":") {
+				title += ":"
+			}
+			model.Groups = append(model.Groups, GroupHelp{Title: title,
+				Desc: group.Desc, Exclusive: group.Exclusive,
+				Options: options})
+		}
+	}
+	if model.Brief {
+		model.LongDesc = ""
+		model.EndDesc = ""
+		model.PositionalHelp = ""
+		for i := range model.Groups {
+			model.Groups[i].Desc = ""
+		}
+		return model
+	}
+	for _, example := range me.examples {
+		model.Examples = append(model.Examples, ExampleHelp{
+			CmdLine:     me.appName + " " + example.cmdline,
+			Explanation: example.explanation})
+	}
+	return model
+}
+
+// Returns true if the option isn't shown in the help at the given level.
+func isOmitted(option optioner, level helpLevel) bool {
+	return (option.isHidden() && level != allHelp) ||
+		(option.isAdvanced() && level == briefHelp)
+}
+
+func (me *Parser) optionsHelp(group *OptionGroup,
+	level helpLevel,
+) []OptionHelp {
+	options := me.groupOptions(group)
+	help := make([]OptionHelp, 0, len(options)+1)
+	for _, option := range options {
+		if isOmitted(option, level) {
+			continue
+		}
+		optionHelp := OptionHelp{LongName: "--" + option.LongName(),
+			ArgText: optArgText(option), Help: me.helpText(option),
+			LongHelp: option.LongHelp(), Default: defaultOf(option)}
+		if option.ShortName() != NoShortName {
+			optionHelp.ShortName = "-" + string(option.ShortName())
+		}
+		help = append(help, optionHelp)
+	}
+	return help
+}
+
+// OnHelp shows the help text and quits. See also [Parser.OnTopicHelp].
+func (me *Parser) OnHelp() {
+	_ = me.onHelp(fullHelp)
+}
+
+// OnTopicHelp shows the help for the option with the given long name, or
+// for the topic added with [Parser.HelpTopic], or the list of topics (for
+// "topics"), and quits.
+func (me *Parser) OnTopicHelp(topic string) {
+	_ = me.onTopicHelp(topic)
+}
+
+func (me *Parser) onHelp(level helpLevel) error {
+	text, err := me.renderHelp(me.helpModel(level))
+	if err != nil {
+		return me.handleError(eInvalidHelpTemplate,
+			"invalid help template: "+err.Error())
+	}
+	return me.showHelp(text)
+}
+
+// Shows the help text (using a pager if wanted) and quits.
+func (me *Parser) showHelp(text string) error {
+	if me.page(text) {
+		text = "" // already shown
+	}
+	return me.exit(0, text)
+}
+
+func (me *Parser) renderHelp(model HelpModel) (string, error) {
+	text := me.HelpTemplate
+	if text == "" {
+		text = DefaultHelpTemplate
+	}
+	tmpl, err := template.New("help").Funcs(me.helpFuncs(&model)).Parse(
+		text)
+	if err != nil {
+		return "", err
+	}
+	var out strings.Builder
+	if err = tmpl.Execute(&out, model); err != nil {
+		return "", err
+	}
+	return strings.TrimSuffix(out.String(), "\n"), nil
+}
+
+func (me *Parser) helpFuncs(model *HelpModel) template.FuncMap {
+	gapWidth := utf8.RuneCountInString(columnGap)
+	maxLeft := 0
+	allFit := true
+	if !me.AlignGroupsSeparately {
+		all := me.optionsData(model.Options)
+		for _, group := range model.Groups {
+			all = append(all, me.optionsData(group.Options)...)
+		}
+		maxLeft = maxArgWidth(all)
+		allFit = prepareOptionsData(maxLeft, gapWidth, model.Width, all)
+	}
+	return template.FuncMap{
+		"wrap": func(text string) string {
+			return uterm.Wrapped(text, model.Width)
+		},
+		"indent": func(text string) string {
+			return uterm.WrappedIndent(text, model.Width, columnGap)
+		},
+		"column": func(arg, help string) string {
+			text := columnGap + arg
+			if help == "" {
+				return text + "\n"
+			}
+			return text + columnGap + ArgHelp(utf8.RuneCountInString(arg),
+				model.Width, help)
+		},
+		"positionals": func(positionals []PositionalArgHelp) string {
+			data := make([]datum, 0, len(positionals))
+			for _, positional := range positionals {
+				data = append(data, namedDatum(positional.Name,
+					positional.Help))
+			}
+			return namedDataText(gapWidth, model.Width, data)
+		},
+		"commands": func(commands []CommandHelp) string {
+			data := make([]datum, 0, len(commands))
+			for _, command := range commands {
+				data = append(data, namedDatum(command.Name, command.Help))
+			}
+			return namedDataText(gapWidth, model.Width, data)
+		},
+		"options": func(options []OptionHelp) string {
+			data := me.optionsData(options)
+			maxLeft, allFit := maxLeft, allFit
+			if me.AlignGroupsSeparately {
+				maxLeft = maxArgWidth(data)
+				allFit = prepareOptionsData(maxLeft, gapWidth, model.Width,
+					data)
+			}
+			return optionsDataText(allFit, maxLeft, gapWidth, model.Width,
+				data)
+		},
+		"example": func(example ExampleHelp) string {
+			return me.exampleText(example, model.Width)
+		},
+		"strong": func(text string) string {
+			return me.style(me.Theme.OptionName, text)
+		},
+		"emph": func(text string) string {
+			return me.style(me.Theme.Heading, text)
+		},
+		"hint": func(text string) string {
+			if onWindows {
+				return me.style(sgrItalic, text)
+			}
+			return me.style(sgrUnderline, text)
+		},
+	}
+}
+
+func (me *Parser) exampleText(example ExampleHelp, width int) string {
+	text := columnGap + me.style(me.Theme.OptionName, example.CmdLine) + "\n"
+	if example.Explanation == "" {
+		return text
+	}
+	indent := strings.Repeat(columnGap, 4)
+	return text + uterm.WrappedIndent(example.Explanation,
+		width-utf8.RuneCountInString(indent), indent) + "\n"
+}
+
+func (me *Parser) optionsData(options []OptionHelp) []datum {
+	data := make([]datum, 0, len(options))
+	for _, option := range options {
+		longName := me.style(me.Theme.OptionName, option.LongName)
+		arg := columnGap + "    " + option.LongName
+		displayArg := columnGap + "    " + longName
+		if option.ShortName != "" {
+			arg = columnGap + option.ShortName + ", " + option.LongName
+			displayArg = columnGap + me.style(me.Theme.OptionName,
+				option.ShortName) + ", " + longName
+		}
+		data = append(data, datum{
+			arg:    displayArg + me.argTextStyled(option.ArgText),
+			lenArg: utf8.RuneCountInString(arg + option.ArgText),
+			help:   option.Help})
+	}
+	return data
+}
+
+// Returns the arg text, e.g., " <LANG1> [LANG2 ...]" styled using the
+// Theme's VarName.
+func (me *Parser) argTextStyled(argText string) string {
+	if argText == "" {
+		return ""
+	}
+	return " " + me.style(me.Theme.VarName, strings.TrimPrefix(argText, " "))
+}
+
+// Returns the datum for a named positional or subcommand.
+func namedDatum(name, help string) datum {
+	arg := columnGap + name
+	return datum{arg: arg, lenArg: utf8.RuneCountInString(arg), help: help}
+}
+
+// Returns the named positionals' or subcommands' help text.
+func namedDataText(gapWidth, width int, data []datum) string {
+	maxLeft := maxArgWidth(data)
+	allFit := prepareOptionsData(maxLeft, gapWidth, width, data)
+	return optionsDataText(allFit, maxLeft, gapWidth, width, data)
+}
+
+func maxArgWidth(data []datum) int {
+	maxLeft := 0
+	for _, datum := range data {
+		maxLeft = max(maxLeft, datum.lenArg)
+	}
+	return maxLeft
+}
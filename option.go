@@ -6,6 +6,8 @@ package clip
 import (
 	"fmt"
 	"regexp"
+	"slices"
+	"strconv"
 	"strings"
 )
 
@@ -16,21 +18,64 @@ type optioner interface {
 	SetVarName(string) error
 	MustSetVarName(string)
 	Help() string
+	LongHelp() string
+	SetLongHelp(string)
 	Hide()
+	Advanced()
+	Given() bool
 	isHidden() bool
+	isAdvanced() bool
+	optionGroup() *OptionGroup
+	setOptionGroup(*OptionGroup)
+	common() *commonOption
 	addValue(string) string
 	wantsValue() bool
 	setGiven()
 	check() string
+	reset()
+}
+
+// Implemented by options (e.g., generic ones) that provide their own
+// argument text for the help.
+type argTexter interface {
+	argText() string
+}
+
+// Implemented by generic options to give their value's text (e.g., for
+// [Parser.ToFlagSet]).
+type valueTexter interface {
+	valueText() string
+}
+
+// Implemented by options which can show their default (if not "") in the
+// help.
+type defaultTexter interface {
+	defaultText() string
+}
+
+// Implemented by multi-value options to check that their range of values
+// is possible.
+type valueCountChecker interface {
+	checkValueCount() string
 }
 
 type commonOption struct {
 	longName  string
 	shortName rune
 	help      string
+	longHelp  string
 	varName   string // e.g., -o|--outfile FILE
 	hidden    bool
+	advanced  bool
 	state     optionState
+	group     *OptionGroup
+
+	// These are only used for the help
+	showDefault defaultDisplay
+	defaultText string   // If not "", shown instead of the default.
+	minimum     string   // The minimum if the option has a range.
+	maximum     string   // The maximum if the option has a range.
+	choices     []string // The choices if the option has choices.
 }
 
 // LongName returns the option's long name.
@@ -55,8 +100,20 @@ func (me *commonOption) Help() string {
 	return me.help
 }
 
+// LongHelp returns the option's long help text (or "" if it has none).
+func (me *commonOption) LongHelp() string {
+	return me.longHelp
+}
+
+// SetLongHelp sets the option's long help text which is shown (instead of
+// its help text) when --help=NAME is given for the option.
+func (me *commonOption) SetLongHelp(longHelp string) {
+	me.longHelp = longHelp
+}
+
 // Hide sets the option to be hidden: the user can use it normally, but it
-// won't show up when -h or --help is given.
+// won't show up when -h or --help is given (only when --help-all is
+// given).
 func (me *commonOption) Hide() {
 	me.hidden = true
 }
@@ -65,6 +122,54 @@ func (me *commonOption) isHidden() bool {
 	return me.hidden
 }
 
+// Advanced sets the option to be advanced: it is shown when --help is
+// given, but not when -h is given if [Parser.BriefHelp] is true.
+func (me *commonOption) Advanced() {
+	me.advanced = true
+}
+
+func (me *commonOption) isAdvanced() bool {
+	return me.advanced
+}
+
+// ShowDefault sets the option's default to be shown in the help—even if
+// [Parser.ShowDefaults] is false. See also HideDefault and
+// SetDefaultText.
+func (me *commonOption) ShowDefault() {
+	me.showDefault = alwaysShowDefault
+}
+
+// HideDefault sets the option's default to not be shown in the help—even
+// if [Parser.ShowDefaults] is true.
+func (me *commonOption) HideDefault() {
+	me.showDefault = neverShowDefault
+}
+
+// SetDefaultText sets the text to show in the help for the option's
+// default (e.g., "all known" rather than a long list of values), and sets
+// the default to be shown (see ShowDefault).
+func (me *commonOption) SetDefaultText(text string) {
+	me.defaultText = text
+	me.showDefault = alwaysShowDefault
+}
+
+func (me *commonOption) setRange(minimum, maximum string) {
+	me.minimum = minimum
+	me.maximum = maximum
+}
+
+func (me *commonOption) common() *commonOption {
+	return me
+}
+
+func (me *commonOption) optionGroup() *OptionGroup {
+	return me.group
+}
+
+func (me *commonOption) setOptionGroup(group *OptionGroup) {
+	me.group = group
+}
+
 // VarName returns the name used for the option's variables: by default the
 // option's long name uppercased. (This is never used by FlagOptions.)
 func (me *commonOption) VarName() string {
@@ -104,6 +209,11 @@ func (me *commonOption) setGiven() {
 	}
 }
 
+// Makes the option as it was before parsing.
+func (me *commonOption) reset() {
+	me.state = notGiven
+}
+
 // FlagOption is an option for a flag (i.e., an option that is either
 // present or absent).
 type FlagOption struct {
@@ -139,6 +249,11 @@ func (me *FlagOption) addValue(value string) string {
 	return "flag " + me.LongName() + " can't accept a value"
 }
 
+func (me *FlagOption) reset() {
+	me.commonOption.reset()
+	me.value = false
+}
+
 // IntOption is an option for accepting a single int.
 type IntOption struct {
 	*commonOption
@@ -182,6 +297,10 @@ func (me IntOption) check() string {
 	return ""
 }
 
+func (me IntOption) defaultText() string {
+	return strconv.Itoa(me.TheDefault)
+}
+
 func (me *IntOption) addValue(value string) string {
 	i, msg := me.Validator(me.longName, value)
 	if msg != "" {
@@ -236,6 +355,10 @@ func (me RealOption) check() string {
 	return ""
 }
 
+func (me RealOption) defaultText() string {
+	return realText(me.TheDefault)
+}
+
 func (me *RealOption) addValue(value string) string {
 	r, msg := me.Validator(me.longName, value)
 	if msg != "" {
@@ -289,6 +412,10 @@ func (me StrOption) check() string {
 	return ""
 }
 
+func (me StrOption) defaultText() string {
+	return me.TheDefault
+}
+
 func (me *StrOption) addValue(value string) string {
 	s, msg := me.Validator(me.longName, value)
 	if msg != "" {
@@ -299,10 +426,74 @@ func (me *StrOption) addValue(value string) string {
 	return ""
 }
 
+// PathOption is an option for accepting a single file or folder path.
+type PathOption struct {
+	*commonOption
+	TheDefault    string    // The options default value.
+	AllowImplicit bool      // If true, giving the option with no value means use the default.
+	Checks        PathCheck // The transformations and checks to apply.
+	value         string
+}
+
+// Always returns a *PathOption; _and_ either nil or error.
+func newPathOption(name, help, theDefault string) (*PathOption, error) {
+	err := checkName(name, "option")
+	shortName, longName := namesForName(name)
+	return &PathOption{commonOption: &commonOption{longName: longName,
+		shortName: shortName, help: help, state: notGiven},
+		TheDefault: theDefault}, err
+}
+
+// Value returns the given value or if the option wasn't given, the default
+// value. The default value is expanded and made absolute as per the
+// option's Checks, but is not otherwise checked.
+func (me PathOption) Value() string {
+	if me.state == hadValue {
+		return me.value
+	}
+	if me.TheDefault == "" {
+		return ""
+	}
+	return transformPath(me.TheDefault, me.Checks)
+}
+
+func (me PathOption) wantsValue() bool {
+	return me.state == given
+}
+
+func (me PathOption) check() string {
+	if me.state == given {
+		if me.AllowImplicit {
+			return ""
+		} else {
+			return "expected exactly one value for " + me.LongName() +
+				", got none"
+		}
+	}
+	return ""
+}
+
+func (me PathOption) defaultText() string {
+	return me.TheDefault
+}
+
+func (me *PathOption) addValue(value string) string {
+	path, msg := checkPath("option "+me.longName+"'s path", value,
+		me.Checks)
+	if msg != "" {
+		return msg
+	}
+	me.value = path
+	me.state = hadValue
+	return ""
+}
+
 // StrsOption is an option for accepting a one or more strings.
 type StrsOption struct {
 	*commonOption
 	ValueCount ValueCount   // How many strings are wanted.
+	MinValues  int          // If not 0, overrides the ValueCount's minimum.
+	MaxValues  int          // If not 0, overrides its maximum (or Unlimited).
 	Validator  StrValidator // A validation function.
 	value      []string
 }
@@ -327,7 +518,13 @@ func (me StrsOption) wantsValue() bool {
 }
 
 func (me StrsOption) check() string {
-	return checkMulti(me.LongName(), me.state, me.ValueCount, len(me.value))
+	return checkMulti(me.LongName(), me.state, me.ValueCount, me.MinValues,
+		me.MaxValues, len(me.value))
+}
+
+func (me StrsOption) checkValueCount() string {
+	return checkValueBounds(me.LongName(), me.ValueCount, me.MinValues,
+		me.MaxValues)
 }
 
 func (me *StrsOption) addValue(value string) string {
@@ -343,10 +540,17 @@ func (me *StrsOption) addValue(value string) string {
 	return ""
 }
 
+func (me *StrsOption) reset() {
+	me.commonOption.reset()
+	me.value = nil
+}
+
 // IntsOption is an option for accepting a one or more ints.
 type IntsOption struct {
 	*commonOption
 	ValueCount ValueCount   // How many ints are wanted.
+	MinValues  int          // If not 0, overrides the ValueCount's minimum.
+	MaxValues  int          // If not 0, overrides its maximum (or Unlimited).
 	Validator  IntValidator // A validation function.
 	value      []int
 }
@@ -371,7 +575,13 @@ func (me IntsOption) wantsValue() bool {
 }
 
 func (me IntsOption) check() string {
-	return checkMulti(me.LongName(), me.state, me.ValueCount, len(me.value))
+	return checkMulti(me.LongName(), me.state, me.ValueCount, me.MinValues,
+		me.MaxValues, len(me.value))
+}
+
+func (me IntsOption) checkValueCount() string {
+	return checkValueBounds(me.LongName(), me.ValueCount, me.MinValues,
+		me.MaxValues)
 }
 
 func (me *IntsOption) addValue(value string) string {
@@ -387,10 +597,17 @@ func (me *IntsOption) addValue(value string) string {
 	return ""
 }
 
+func (me *IntsOption) reset() {
+	me.commonOption.reset()
+	me.value = nil
+}
+
 // RealsOption is an option for accepting a one or more reals.
 type RealsOption struct {
 	*commonOption
 	ValueCount ValueCount    // How many strings are wanted.
+	MinValues  int           // If not 0, overrides the ValueCount's minimum.
+	MaxValues  int           // If not 0, overrides its maximum (or Unlimited).
 	Validator  RealValidator // A validation function.
 	value      []float64
 }
@@ -415,7 +632,13 @@ func (me RealsOption) wantsValue() bool {
 }
 
 func (me RealsOption) check() string {
-	return checkMulti(me.LongName(), me.state, me.ValueCount, len(me.value))
+	return checkMulti(me.LongName(), me.state, me.ValueCount, me.MinValues,
+		me.MaxValues, len(me.value))
+}
+
+func (me RealsOption) checkValueCount() string {
+	return checkValueBounds(me.LongName(), me.ValueCount, me.MinValues,
+		me.MaxValues)
 }
 
 func (me *RealsOption) addValue(value string) string {
@@ -431,6 +654,117 @@ func (me *RealsOption) addValue(value string) string {
 	return ""
 }
 
+func (me *RealsOption) reset() {
+	me.commonOption.reset()
+	me.value = nil
+}
+
+// IntRange is an inclusive range of ints, e.g., 1-5 is IntRange{1, 5} and 8
+// is IntRange{8, 8}.
+type IntRange struct {
+	Start int
+	End   int
+}
+
+func (me IntRange) String() string {
+	if me.Start == me.End {
+		return strconv.Itoa(me.Start)
+	}
+	return fmt.Sprintf("%d-%d", me.Start, me.End)
+}
+
+// IntRangesOption is an option for accepting a comma-separated list of ints
+// and int ranges, e.g., 1-5,8,10-12.
+type IntRangesOption struct {
+	*commonOption
+	Validator IntRangesValidator // A validation function.
+	value     []IntRange
+	ints      []int // The value's ints (expanded, sorted, and unique).
+}
+
+// The most ints that an IntRangesOption's ranges may span in total.
+const maxIntRangesSpan = 1_000_000
+
+// Always returns a *IntRangesOption; _and_ either nil or error.
+func newIntRangesOption(name, help string, minimum,
+	maximum int,
+) (*IntRangesOption, error) {
+	err := checkName(name, "option")
+	if err == nil && (minimum < 0 || maximum < minimum) {
+		err = fmt.Errorf("#%d: invalid int ranges limits %d to %d for %s",
+			eInvalidValue, minimum, maximum, name)
+	}
+	shortName, longName := namesForName(name)
+	return &IntRangesOption{commonOption: &commonOption{longName: longName,
+		shortName: shortName, help: help, state: notGiven},
+		Validator: makeIntRangesValidator(minimum, maximum)}, err
+}
+
+// Value returns the given ints (expanded, sorted, and with duplicates
+// removed) and the given ranges (in the order given); or nil and nil.
+func (me IntRangesOption) Value() ([]int, []IntRange) {
+	return me.ints, me.value
+}
+
+// Contains returns true if i is in any of the given ranges.
+func (me IntRangesOption) Contains(i int) bool {
+	for _, intRange := range me.value {
+		if i >= intRange.Start && i <= intRange.End {
+			return true
+		}
+	}
+	return false
+}
+
+func (me IntRangesOption) wantsValue() bool {
+	return me.state == given
+}
+
+func (me IntRangesOption) check() string {
+	if me.state == given {
+		return "expected exactly one value for " + me.LongName() +
+			", got none"
+	}
+	return ""
+}
+
+func (me *IntRangesOption) addValue(value string) string {
+	ranges, msg := me.Validator(me.longName, value)
+	if msg != "" {
+		return msg
+	}
+	var span uint64
+	for _, intRange := range ranges {
+		// Unsigned so that the span of any valid range can't overflow.
+		if uint64(intRange.End)-uint64(intRange.Start) >=
+			maxIntRangesSpan-span {
+			return fmt.Sprintf("option %s's ranges span more than %d ints",
+				me.longName, maxIntRangesSpan)
+		}
+		span += uint64(intRange.End) - uint64(intRange.Start) + 1
+	}
+	ints := make([]int, 0, span)
+	for _, intRange := range ranges {
+		for i := intRange.Start; ; i++ { // can't overflow at math.MaxInt
+			ints = append(ints, i)
+			if i == intRange.End {
+				break
+			}
+		}
+	}
+	slices.Sort(ints)
+	me.ints = slices.Compact(ints)
+	me.value = ranges
+	me.state = hadValue
+	return ""
+}
+
+func (me *IntRangesOption) reset() {
+	me.commonOption.reset()
+	me.value = nil
+	me.ints = nil
+}
+
 func checkName(name, what string) error {
 	rx := regexp.MustCompile(`^\pL[-\pL\pNd_]*$`)
 	if rx.MatchString(name) {
@@ -441,37 +775,49 @@ func checkName(name, what string) error {
 }
 
 func checkMulti(name string, state optionState, valueCount ValueCount,
-	count int) string {
-	if state == given {
-		return fmt.Sprintf(
-			"expected %s values for %s, got none", valueCount, name)
-	} else if state == hadValue {
-		ok := true
-		switch valueCount {
-		case OneOrMoreValues:
-			if count < 1 {
-				ok = false
-			}
-		case TwoValues:
-			if count != 2 {
-				ok = false
-			}
-		case ThreeValues:
-			if count != 3 {
-				ok = false
-			}
-		case FourValues:
-			if count != 3 {
-				ok = false
-			}
-		default:
-			return fmt.Sprintf("#%d:BUG:impossible ValueCount", eBug)
+	minValues, maxValues, count int) string {
+	if state == notGiven {
+		return ""
+	}
+	minimum, maximum := valueBounds(valueCount, minValues, maxValues)
+	if count < minimum || (maximum != Unlimited && count > maximum) {
+		expected := valueCount.String()
+		if minValues != 0 || maxValues != 0 {
+			expected = countRangeText(minimum, maximum)
 		}
-		if !ok {
-			return fmt.Sprintf(
-				"expected %s values for %s, got %d", valueCount, name,
-				count)
+		got := "none"
+		if count > 0 {
+			got = strconv.Itoa(count)
 		}
+		return fmt.Sprintf("expected %s values for %s, got %s", expected,
+			name, got)
+	}
+	return ""
+}
+
+// Returns the minimum and maximum (which may be Unlimited) number of
+// values wanted: minValues and maxValues, except that either which is 0 is
+// taken from the ValueCount.
+func valueBounds(valueCount ValueCount, minValues, maxValues int) (int,
+	int) {
+	minimum, maximum := valueCount.bounds()
+	if minValues != 0 {
+		minimum = minValues
+	}
+	if maxValues != 0 {
+		maximum = maxValues
+	}
+	return minimum, maximum
+}
+
+// Returns "" or an error message if the range of values wanted is
+// impossible.
+func checkValueBounds(name string, valueCount ValueCount, minValues,
+	maxValues int) string {
+	minimum, maximum := valueBounds(valueCount, minValues, maxValues)
+	if minimum < 0 || (maximum != Unlimited && maximum < minimum) {
+		return fmt.Sprintf("#%d: invalid value count range %d to %d for %s",
+			eInvalidValueCount, minimum, maximum, name)
 	}
 	return ""
 }
@@ -0,0 +1,59 @@
+// Copyright © 2022 Mark Summerfield. All rights reserved.
+// License: Apache-2.0
+
+package clip
+
+import (
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+
+	tsize "github.com/kopoli/go-terminal-size"
+)
+
+const defaultPager = "less -R" // -R preserves Strong and Emph
+
+// Shows the help text using a pager if the Parser's UsePager is true, the
+// text is too tall for the terminal, and a pager is wanted and works.
+// Returns true if the text was shown.
+func (me *Parser) page(text string) bool {
+	if !me.UsePager || !stdoutTerminal || (me.Stdout != nil &&
+		me.Stdout != io.Writer(os.Stdout)) {
+		return false
+	}
+	command := pagerCommand()
+	if command == nil {
+		return false
+	}
+	if size, err := tsize.GetSize(); err == nil &&
+		strings.Count(text, "\n")+1 < size.Height {
+		return false
+	}
+	cmd := exec.Command(command[0], command[1:]...)
+	cmd.Stdin = strings.NewReader(text + "\n")
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	return cmd.Run() == nil
+}
+
+// Returns the pager command and its arguments: $PAGER if set, or less -R;
+// or nil if the environment says not to page (i.e., TERM is dumb, NO_PAGER
+// is set, or PAGER is set to "" or cat).
+func pagerCommand() []string {
+	if os.Getenv("TERM") == "dumb" {
+		return nil
+	}
+	if _, ok := os.LookupEnv("NO_PAGER"); ok {
+		return nil
+	}
+	pager, ok := os.LookupEnv("PAGER")
+	if !ok {
+		pager = defaultPager
+	}
+	command := strings.Fields(pager)
+	if len(command) == 0 || command[0] == "cat" {
+		return nil
+	}
+	return command
+}
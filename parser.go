@@ -4,13 +4,14 @@
 package clip
 
 import (
+	"encoding"
 	"fmt"
+	"io"
+	"math"
 	"os"
+	"slices"
 	"strconv"
 	"strings"
-	"unicode/utf8"
-
-	"github.com/mark-summerfield/uterm"
 )
 
 // For applications with fairly simple CLIs, only the LongDesc is used.
@@ -28,11 +29,93 @@ type Parser struct {
 	Positionals       []string        // The positionals (after parsing).
 	PositionalCount   PositionalCount // How many positionals are wanted.
 	PositionalHelp    string          // The positionals help text.
+	PositionalKind    PathCheck       // The positionals path checks.
+
+	// Whether options may follow positionals: see [ArgOrder]. The default
+	// is POSIXOrder. (If the POSIXLY_CORRECT environment variable is set,
+	// GNUOrder is treated as POSIXOrder.)
+	ArgOrder ArgOrder
+
+	// If true, unrecognized options are added to Unknown (in the order
+	// given, with their values if attached, e.g., --name=value or -nvalue)
+	// rather than being errors. (Unattached values are positionals.) This
+	// is useful for wrappers that pass options on to another program.
+	CollectUnknown bool
+	Unknown        []string // The unrecognized options (after parsing).
+
+	// If true, each option's choices, range, and default (if any) are
+	// appended to its help text using the ChoicesFormat, RangeFormat, and
+	// DefaultFormat (any of which may be "" to not show that information).
+	// See also each option's ShowDefault, HideDefault, and SetDefaultText
+	// methods.
+	ShowDefaults  bool
+	ChoicesFormat string // Default "[choices: %s]"; %s is the choices.
+	RangeFormat   string // Default "[range: %s..%s]"; %s's are min & max.
+	DefaultFormat string // Default "[default: %s]"; %s is the default.
+
+	// If true, each group's options are aligned independently of the
+	// other groups' options; otherwise all the options are aligned
+	// together. See [Parser.Group].
+	AlignGroupsSeparately bool
+
+	// If true, -h shows brief help: the usage, positionals, and options
+	// (except for advanced ones; see each option's Advanced method), with
+	// no descriptions or examples; whereas --help shows the full help. In
+	// either case, --help-all shows the full help including hidden
+	// options.
+	BriefHelp bool
+
+	// The styles used for the help and error messages: see [Theme].
+	Theme Theme
+
+	// When to style the help (on stdout) and error messages (on stderr).
+	// If ColorAuto (the default), each is styled if it is a terminal,
+	// unless NO_COLOR is set (not to ""), or FORCE_COLOR is set (not to ""
+	// or "0"), or TERM is dumb.
+	Color ColorMode
+
+	// If not "" (the default), an option with this long name (e.g.,
+	// "color") is added that the user can give as --color=auto|always|never
+	// to override Color.
+	ColorName string
+
+	// Where the help and version (Stdout) and error messages (Stderr) are
+	// written, and the function called to quit: if any of these are nil,
+	// os.Stdout, os.Stderr, and os.Exit are used. If Exit returns, the
+	// parsing methods return an [*ExitError]. (Set these to make a Parser
+	// independent of the process, e.g., in tests or servers.)
+	Stdout io.Writer
+	Stderr io.Writer
+	Exit   func(int)
+
+	// If true, and stdout is a terminal, help that is too tall for the
+	// terminal is shown using $PAGER (or less -R if PAGER isn't set). The
+	// pager isn't used if TERM is dumb, NO_PAGER is set, or PAGER is set
+	// to "" or cat.
+	UsePager bool
+
+	// A [text/template] rendered against the [HelpModel] to produce the
+	// help; if "" (the default), [DefaultHelpTemplate] is used.
+	HelpTemplate string
 
 	positionalVarName1 string // Name of first positional. Default "FILE".
 	positionalVarNameN string // Name of subsequent positionals. Same default.
+	positionalMin      int    // Only used if positionalRange is true.
+	positionalMax      int    // May be Unlimited.
+	positionalRange    bool   // True if SetPositionalRange was called.
 	useLowerhForHelp   bool
 	width              int
+	groups             []*OptionGroup
+	positionals        []positionaler // Named positionals (if any).
+	examples           []example
+	prepared           bool      // True once the version option is added.
+	givenColor         ColorMode // Only used if colorGiven is true.
+	colorGiven         bool      // True if --color=MODE was given.
+	topics             []helpTopic
+	afterParse         []func()        // Called after a successful parse.
+	flagSetters        []func() string // Called before afterParse.
+	subcommands        []*subcommand
+	command            string // The subcommand given (if any).
 }
 
 // NewParser creates a new command line parser.
@@ -64,7 +147,9 @@ func NewParserUser(appname, version string) Parser {
 		options:         []optioner{},
 		PositionalCount: ZeroOrMorePositionals, positionalVarName1: "FILE",
 		HelpName: "help", VersionName: "version", useLowerhForHelp: true,
-		width: GetWidth(),
+		width: GetWidth(), ChoicesFormat: "[choices: %s]",
+		RangeFormat: "[range: %s..%s]", DefaultFormat: "[default: %s]",
+		Theme: DefaultTheme(),
 	}
 }
 
@@ -112,6 +197,51 @@ func (me *Parser) MustSetPositionalVarName(names ...string) {
 	}
 }
 
+// SetPositionalRange sets how many positionals are wanted, from minimum
+// to maximum inclusive (maximum may be [Unlimited]); this overrides the
+// PositionalCount. See also [MustSetPositionalRange].
+//
+//	parser.SetPositionalRange(2, 6) // two to six
+//	parser.SetPositionalRange(3, Unlimited) // at least three
+func (me *Parser) SetPositionalRange(minimum, maximum int) error {
+	if minimum < 0 || (maximum != Unlimited && maximum < minimum) {
+		return fmt.Errorf("#%d: invalid positional range %d to %d",
+			eInvalidPositional, minimum, maximum)
+	}
+	me.positionalMin = minimum
+	me.positionalMax = maximum
+	me.positionalRange = true
+	return nil
+}
+
+// MustSetPositionalRange sets how many positionals are wanted, from
+// minimum to maximum inclusive (maximum may be [Unlimited]). Panics on
+// error. See also [SetPositionalRange].
+func (me *Parser) MustSetPositionalRange(minimum, maximum int) {
+	if err := me.SetPositionalRange(minimum, maximum); err != nil {
+		panic(err)
+	}
+}
+
+// Returns the minimum and maximum (which may be Unlimited) number of
+// positionals wanted.
+func (me *Parser) positionalBounds() (int, int) {
+	if me.positionalRange {
+		return me.positionalMin, me.positionalMax
+	}
+	return me.PositionalCount.bounds()
+}
+
+// Group creates and returns a new [OptionGroup] with the given title (e.g.,
+// "Input") and description (which may be empty). Add options to the group
+// using [OptionGroup.Add]. Groups are shown in the help in the order they
+// were created, after the options that aren't in any group.
+func (me *Parser) Group(title, desc string) *OptionGroup {
+	group := &OptionGroup{Title: title, Desc: desc}
+	me.groups = append(me.groups, group)
+	return group
+}
+
 // Flag creates and returns a new [FlagOption], --name or -n (where n is the
 // first rune in name) and help is the option's help text.
 func (me *Parser) Flag(name, help string) *FlagOption {
@@ -137,6 +267,34 @@ func (me *Parser) IntInRange(name, help string, minimum, maximum,
 ) *IntOption {
 	option, err := newIntOption(name, help, theDefault)
 	option.Validator = makeIntRangeValidator(minimum, maximum)
+	option.setRange(strconv.Itoa(minimum), strconv.Itoa(maximum))
+	me.registerNewOption(option, err)
+	return option
+}
+
+// IntRanges creates and returns a new [IntRangesOption], --name or -n (where
+// n is the first rune in name) and help is the option's help text. The
+// option accepts comma-separated ints and int ranges, e.g., 1-5,8,10-12.
+// Ranges may be open at the start (e.g., -3 means 0-3) but not at the end
+// (use [Parser.IntRangesInRange] for that). The ranges may span at most a
+// million ints in total (see also [IntRangesOption.Contains]).
+func (me *Parser) IntRanges(name, help string) *IntRangesOption {
+	option, err := newIntRangesOption(name, help, 0, math.MaxInt)
+	me.registerNewOption(option, err)
+	return option
+}
+
+// IntRangesInRange creates and returns a new [IntRangesOption], --name or
+// -n (where n is the first rune in name), help is the option's help text,
+// and the minimum and maximum are inclusive limits. The option accepts
+// comma-separated ints and int ranges, e.g., 1-5,8,10-12, and open ranges
+// which are clamped to the limits, e.g., 5- means 5-maximum and -3 means
+// minimum-3. (So the minimum can't be negative.)
+func (me *Parser) IntRangesInRange(name, help string,
+	minimum, maximum int,
+) *IntRangesOption {
+	option, err := newIntRangesOption(name, help, minimum, maximum)
+	option.setRange(strconv.Itoa(minimum), strconv.Itoa(maximum))
 	me.registerNewOption(option, err)
 	return option
 }
@@ -160,6 +318,7 @@ func (me *Parser) RealInRange(name, help string, minimum, maximum,
 ) *RealOption {
 	option, err := newRealOption(name, help, theDefault)
 	option.Validator = makeRealRangeValidator(minimum, maximum)
+	option.setRange(realText(minimum), realText(maximum))
 	me.registerNewOption(option, err)
 	return option
 }
@@ -173,6 +332,54 @@ func (me *Parser) Str(name, help, theDefault string) *StrOption {
 	return option
 }
 
+// Path creates and returns a new [PathOption], --name or -n (where n is the
+// first rune in name), help is the option's help text, and theDefault is
+// the option's default. By default no transformations or checks are
+// applied: set the option's Checks to add them (see [PathCheck]).
+func (me *Parser) Path(name, help, theDefault string) *PathOption {
+	option, err := newPathOption(name, help, theDefault)
+	me.registerNewOption(option, err)
+	return option
+}
+
+// InputFile creates and returns a new [InputFileOption], --name or -n
+// (where n is the first rune in name), help is the option's help text, and
+// theDefault is the option's default filename (e.g., "-" for stdin).
+func (me *Parser) InputFile(name, help, theDefault string) *InputFileOption {
+	option, err := newInputFileOption(name, help, theDefault)
+	me.registerNewOption(option, err)
+	return option
+}
+
+// OutputFile creates and returns a new [OutputFileOption], --name or -n
+// (where n is the first rune in name), help is the option's help text, and
+// theDefault is the option's default filename (e.g., "-" for stdout).
+func (me *Parser) OutputFile(name, help,
+	theDefault string,
+) *OutputFileOption {
+	option, err := newOutputFileOption(name, help, theDefault)
+	me.registerNewOption(option, err)
+	return option
+}
+
+// Text creates and returns a new [TextOption], --name or -n (where n is the
+// first rune in name), help is the option's help text, and ptr is the value
+// the given value is unmarshaled into; its existing value is the default
+// (which is shown in the help if ptr implements [encoding.TextMarshaler]).
+// For multi-value options of such types, see [Texts].
+//
+//	parser := NewParser()
+//	addr := netip.MustParseAddr("127.0.0.1")
+//	parser.Text("addr", "The address to listen on", &addr)
+//	parser.ParseLine("-a 192.168.1.7") // addr is now 192.168.1.7
+func (me *Parser) Text(name, help string,
+	ptr encoding.TextUnmarshaler,
+) *TextOption {
+	option, err := newTextOption(name, help, ptr)
+	me.registerNewOption(option, err)
+	return option
+}
+
 // Choice creates and returns a new [StrOption], --name or -n (where n is
 // the first rune in name), help is the option's help text, choices are the
 // valid choices from which the option's value must be chosen, and
@@ -182,6 +389,7 @@ func (me *Parser) Choice(name, help string, choices []string,
 ) *StrOption {
 	option, err := newStrOption(name, help, theDefault)
 	option.Validator = makeChoiceValidator(choices)
+	option.choices = choices
 	me.registerNewOption(option, err)
 	return option
 }
@@ -213,6 +421,175 @@ func (me *Parser) Reals(name, help string) *RealsOption {
 	return option
 }
 
+// FlagVar creates and returns a new [FlagOption] like [Parser.Flag], and
+// after a successful parse sets *ptr to true if the flag was given or
+// otherwise to *ptr's value when FlagVar was called (so a true default is
+// kept).
+func (me *Parser) FlagVar(ptr *bool, name, help string) *FlagOption {
+	option := me.Flag(name, help)
+	theDefault := *ptr
+	me.afterParse = append(me.afterParse, func() {
+		*ptr = option.Value() || theDefault
+	})
+	return option
+}
+
+// IntVar creates and returns a new [IntOption] like [Parser.Int], and after
+// a successful parse sets *ptr to the option's value.
+func (me *Parser) IntVar(ptr *int, name, help string,
+	theDefault int,
+) *IntOption {
+	option := me.Int(name, help, theDefault)
+	me.afterParse = append(me.afterParse, func() { *ptr = option.Value() })
+	return option
+}
+
+// IntInRangeVar creates and returns a new [IntOption] like
+// [Parser.IntInRange], and after a successful parse sets *ptr to the
+// option's value.
+func (me *Parser) IntInRangeVar(ptr *int, name, help string, minimum,
+	maximum, theDefault int,
+) *IntOption {
+	option := me.IntInRange(name, help, minimum, maximum, theDefault)
+	me.afterParse = append(me.afterParse, func() { *ptr = option.Value() })
+	return option
+}
+
+// RealVar creates and returns a new [RealOption] like [Parser.Real], and
+// after a successful parse sets *ptr to the option's value.
+func (me *Parser) RealVar(ptr *float64, name, help string,
+	theDefault float64,
+) *RealOption {
+	option := me.Real(name, help, theDefault)
+	me.afterParse = append(me.afterParse, func() { *ptr = option.Value() })
+	return option
+}
+
+// RealInRangeVar creates and returns a new [RealOption] like
+// [Parser.RealInRange], and after a successful parse sets *ptr to the
+// option's value.
+func (me *Parser) RealInRangeVar(ptr *float64, name, help string, minimum,
+	maximum, theDefault float64,
+) *RealOption {
+	option := me.RealInRange(name, help, minimum, maximum, theDefault)
+	me.afterParse = append(me.afterParse, func() { *ptr = option.Value() })
+	return option
+}
+
+// StrVar creates and returns a new [StrOption] like [Parser.Str], and after
+// a successful parse sets *ptr to the option's value.
+func (me *Parser) StrVar(ptr *string, name, help,
+	theDefault string,
+) *StrOption {
+	option := me.Str(name, help, theDefault)
+	me.afterParse = append(me.afterParse, func() { *ptr = option.Value() })
+	return option
+}
+
+// ChoiceVar creates and returns a new [StrOption] like [Parser.Choice],
+// and after a successful parse sets *ptr to the option's value.
+func (me *Parser) ChoiceVar(ptr *string, name, help string,
+	choices []string, theDefault string,
+) *StrOption {
+	option := me.Choice(name, help, choices, theDefault)
+	me.afterParse = append(me.afterParse, func() { *ptr = option.Value() })
+	return option
+}
+
+// PathVar creates and returns a new [PathOption] like [Parser.Path], and
+// after a successful parse sets *ptr to the option's value.
+func (me *Parser) PathVar(ptr *string, name, help,
+	theDefault string,
+) *PathOption {
+	option := me.Path(name, help, theDefault)
+	me.afterParse = append(me.afterParse, func() { *ptr = option.Value() })
+	return option
+}
+
+// StrsVar creates and returns a new [StrsOption] like [Parser.Strs], and
+// after a successful parse sets *ptr to the option's values (if the option
+// was given; otherwise to *ptr's values when it was called).
+func (me *Parser) StrsVar(ptr *[]string, name, help string) *StrsOption {
+	option := me.Strs(name, help)
+	me.afterParse = append(me.afterParse, makeSliceSetter(ptr,
+		option.Given, func() []string { return option.Value() }))
+	return option
+}
+
+// IntsVar creates and returns a new [IntsOption] like [Parser.Ints], and
+// after a successful parse sets *ptr to the option's values (if the option
+// was given; otherwise to *ptr's values when it was called).
+func (me *Parser) IntsVar(ptr *[]int, name, help string) *IntsOption {
+	option := me.Ints(name, help)
+	me.afterParse = append(me.afterParse, makeSliceSetter(ptr,
+		option.Given, func() []int { return option.Value() }))
+	return option
+}
+
+// RealsVar creates and returns a new [RealsOption] like [Parser.Reals], and
+// after a successful parse sets *ptr to the option's values (if the option
+// was given; otherwise to *ptr's values when it was called).
+func (me *Parser) RealsVar(ptr *[]float64, name, help string) *RealsOption {
+	option := me.Reals(name, help)
+	me.afterParse = append(me.afterParse, makeSliceSetter(ptr,
+		option.Given, func() []float64 { return option.Value() }))
+	return option
+}
+
+// IntRangesVar creates and returns a new [IntRangesOption] like
+// [Parser.IntRanges], and after a successful parse sets *ptr to the
+// option's ints (if the option was given; otherwise to *ptr's values
+// when it was called).
+func (me *Parser) IntRangesVar(ptr *[]int, name,
+	help string,
+) *IntRangesOption {
+	option := me.IntRanges(name, help)
+	me.afterParse = append(me.afterParse, makeSliceSetter(ptr,
+		option.Given, func() []int {
+			ints, _ := option.Value()
+			return ints
+		}))
+	return option
+}
+
+// Returns a function that sets *ptr to the option's values if it was given
+// and otherwise to a copy of *ptr's values when this was called (so that
+// each parse starts afresh).
+func makeSliceSetter[T any](ptr *[]T, given func() bool,
+	values func() []T,
+) func() {
+	theDefault := slices.Clone(*ptr)
+	return func() {
+		if given() {
+			*ptr = values()
+		} else {
+			*ptr = slices.Clone(theDefault)
+		}
+	}
+}
+
+// InputFileVar creates and returns a new [InputFileOption] like
+// [Parser.InputFile], and after a successful parse sets *ptr to the
+// option's reader (which may be nil; see [InputFileOption.Value]).
+func (me *Parser) InputFileVar(ptr *io.ReadCloser, name, help,
+	theDefault string,
+) *InputFileOption {
+	option := me.InputFile(name, help, theDefault)
+	me.afterParse = append(me.afterParse, func() { *ptr = option.Value() })
+	return option
+}
+
+// OutputFileVar creates and returns a new [OutputFileOption] like
+// [Parser.OutputFile], and after a successful parse sets *ptr to the
+// option's writer (which may be nil; see [OutputFileOption.Value]).
+func (me *Parser) OutputFileVar(ptr *io.WriteCloser, name, help,
+	theDefault string,
+) *OutputFileOption {
+	option := me.OutputFile(name, help, theDefault)
+	me.afterParse = append(me.afterParse, func() { *ptr = option.Value() })
+	return option
+}
+
 func (me *Parser) registerNewOption(option optioner, err error) {
 	me.options = append(me.options, option)
 	if err != nil && me.firstDelayedError == "" {
@@ -254,62 +631,135 @@ func (me *Parser) ParseLine(line string) error {
 	return me.ParseArgs(strings.Fields(line))
 }
 
-// ParseArgs parsess the arguments in the given slice of strings.
+// ParseArgs parsess the arguments in the given slice of strings (after
+// resetting the Parser: see [Parser.Reset]).
 // Each option is assigned the given value or its default (if any), and the
 // Parser.Positionals is filled with the remaining arguments (depending on
 // the Parser.PositionalCount (see [PositionalCount].
 // See also [Parser.Parse] and [Parser.ParseLine].
 func (me *Parser) ParseArgs(args []string) error {
+	me.Reset()
+	me.colorFromArgs(args)
 	if err := me.checkForDelayedError(); err != nil {
 		return err
 	}
 	if err := me.prepareHelpAndVersionOptions(); err != nil {
 		return err
 	}
-	tokens, err := me.tokenize(args)
-	if err != nil {
-		return err
-	}
+	state := me.initializeTokenState()
+	order := me.argOrder()
 	var currentOption optioner
-	inPositionals := false
-	for _, token := range tokens {
-		if token.kind == positionalsFollowTokenKind {
-			inPositionals = true
-		} else if inPositionals {
-			me.addPositional(token.text)
-		} else if token.kind == helpTokenKind {
-			me.OnHelp() // doesn't return
-		} else if token.kind == nameTokenKind { // Option
-			currentOption = token.option
-			if me.isVersion(currentOption) { // may not return
-				return nil
-			}
-			if option, ok := currentOption.(*FlagOption); ok {
-				option.value = true
-			}
-		} else { // Value
-			if currentOption != nil && currentOption.wantsValue() {
-				if msg := currentOption.addValue(token.text); msg != "" {
-					return me.handleError(eInvalidValue, msg)
-				}
-			} else {
+	var tokens []token
+	inPositionals := false // after --, -, or (for POSIXOrder) a positional
+	for i, arg := range args {
+		if len(me.subcommands) > 0 && len(me.Positionals) > 0 {
+			return me.parseSubcommand(args[i:])
+		}
+		if inPositionals {
+			me.addPositional(arg)
+			continue
+		}
+		if order == PassUnknownOrder && len(me.Positionals) > 0 &&
+			me.isUnknownOption(arg, &state) {
+			me.addPositional(arg)
+			currentOption = nil
+			continue
+		}
+		var err error
+		if tokens, err = me.tokenize(arg, tokens, &state); err != nil {
+			return err
+		}
+		for _, token := range tokens {
+			if token.kind == positionalsFollowTokenKind {
 				inPositionals = true
+			} else if inPositionals {
 				me.addPositional(token.text)
+			} else if token.kind == helpTokenKind {
+				if token.text != "" { // --help=NAME
+					return me.onTopicHelp(token.text) // doesn't return
+				}
+				return me.onHelp(token.level) // doesn't return
+			} else if token.kind == unknownTokenKind {
+				me.Unknown = append(me.Unknown, token.text)
+				currentOption = nil
+			} else if token.kind == nameTokenKind { // Option
+				currentOption = token.option
+				if me.isVersion(currentOption) {
+					return me.onVersion() // doesn't return
+				}
+				if option, ok := currentOption.(*FlagOption); ok {
+					option.value = true
+				}
+			} else { // Value
+				if currentOption != nil && currentOption.wantsValue() {
+					if msg := currentOption.addValue(token.text); msg != "" {
+						return me.handleError(eInvalidValue, msg)
+					}
+				} else {
+					me.addPositional(token.text)
+					if order == POSIXOrder {
+						inPositionals = true
+					}
+				}
 			}
 		}
 	}
+	if len(me.subcommands) > 0 {
+		return me.parseSubcommand(nil)
+	}
 	if err := me.checkPositionals(); err != nil {
 		return err
 	}
-	return me.checkValues()
+	return me.finishParse()
+}
+
+// Checks the options' values and then updates the Var-style variables.
+func (me *Parser) finishParse() error {
+	if err := me.checkValues(); err != nil {
+		return err
+	}
+	for _, set := range me.flagSetters { // see FromFlagSet
+		if msg := set(); msg != "" {
+			return me.handleError(eInvalidValue, msg)
+		}
+	}
+	for _, update := range me.afterParse {
+		update()
+	}
+	return nil
+}
+
+// Reset makes the Parser and its options as they were before parsing:
+// no options are given and there are no positionals. There's no need to
+// call this before parsing since each parse resets the Parser (so the
+// same Parser can parse many command lines).
+func (me *Parser) Reset() {
+	for _, option := range me.options {
+		option.reset()
+	}
+	for _, positional := range me.positionals {
+		positional.reset()
+	}
+	for _, sub := range me.subcommands {
+		sub.parser.Reset()
+	}
+	me.Positionals = nil
+	me.Unknown = nil
+	me.command = ""
+	me.colorGiven = false
 }
 
 func (me *Parser) prepareHelpAndVersionOptions() error {
+	if me.prepared {
+		return nil
+	}
+	me.prepared = true
 	usevForVersion := true
 	useVForVersion := false
 	seenV := false
 	for _, option := range me.options {
-		if option.LongName() == me.HelpName {
+		if option.LongName() == me.HelpName ||
+			option.LongName() == me.HelpName+"-all" {
 			return me.handleError(eInvalidHelpOption,
 				"only auto-generated help is supported")
 		} else if option.LongName() == me.VersionName {
@@ -328,6 +778,12 @@ func (me *Parser) prepareHelpAndVersionOptions() error {
 	if me.VersionName != "" && !usevForVersion && !seenV {
 		useVForVersion = true
 	}
+	if me.ColorName != "" {
+		colorOpt := me.Choice(me.ColorName,
+			"When to use color: auto, always, or never.", colorModeNames,
+			colorModeNames[ColorAuto])
+		colorOpt.SetShortName(NoShortName)
+	}
 	if me.VersionName != "" && me.appVersion != "" {
 		versionOpt := me.Flag(me.VersionName, "Show version and quit.")
 		if usevForVersion {
@@ -340,13 +796,34 @@ func (me *Parser) prepareHelpAndVersionOptions() error {
 	return nil
 }
 
+// Also checks what can only be checked once the options and positionals
+// have been set up (e.g., after a positional's MakeOptional).
 func (me *Parser) checkForDelayedError() error {
+	if me.firstDelayedError == "" {
+		me.firstDelayedError = me.checkValueCounts()
+	}
+	if me.firstDelayedError == "" {
+		me.firstDelayedError = me.checkPositionalOrder()
+	}
 	if me.firstDelayedError != "" {
-		exitFunc(2, Hint("error "+me.firstDelayedError))
+		return me.exit(2, "error "+me.firstDelayedError)
 	}
 	return nil
 }
 
+// Returns "" or the first impossible multi-value option value range's
+// error message.
+func (me *Parser) checkValueCounts() string {
+	for _, option := range me.options {
+		if checker, ok := option.(valueCountChecker); ok {
+			if msg := checker.checkValueCount(); msg != "" {
+				return msg
+			}
+		}
+	}
+	return ""
+}
+
 func (me *Parser) addPositional(value string) {
 	if me.Positionals == nil {
 		me.Positionals = make([]string, 0, 1)
@@ -354,58 +831,122 @@ func (me *Parser) addPositional(value string) {
 	me.Positionals = append(me.Positionals, value)
 }
 
+// PositionalReader returns a lazily opened reader for the positional at
+// the given index (or nil if there isn't one). As for [InputFileOption]s,
+// "-" means stdin and files with a .gz suffix are decompressed. The reader
+// must be closed by the caller.
+func (me *Parser) PositionalReader(index int) io.ReadCloser {
+	if index < 0 || index >= len(me.Positionals) {
+		return nil
+	}
+	return &fileReader{filename: me.Positionals[index]}
+}
+
+// PositionalWriter returns a lazily opened atomic writer for the
+// positional at the given index (or nil if there isn't one). As for
+// [OutputFileOption]s, "-" means stdout and files with a .gz suffix are
+// compressed. The writer must be closed by the caller.
+func (me *Parser) PositionalWriter(index int) io.WriteCloser {
+	if index < 0 || index >= len(me.Positionals) {
+		return nil
+	}
+	return &fileWriter{filename: me.Positionals[index], atomic: true}
+}
+
 func (me *Parser) isVersion(option optioner) bool {
 	if option.LongName() == me.VersionName || (me.shortVersionName !=
 		NoShortName && me.shortVersionName == option.ShortName()) {
-		me.onVersion() // doesn't return
 		return true
 	}
 	return false
 }
 
-func (me *Parser) tokenize(args []string) ([]token, error) {
-	var err error
+func (me *Parser) argOrder() ArgOrder {
+	if me.ArgOrder == GNUOrder {
+		if _, ok := os.LookupEnv("POSIXLY_CORRECT"); ok {
+			return POSIXOrder
+		}
+	}
+	return me.ArgOrder
+}
+
+// Returns the tokens for the given arg. The previous tokens are those
+// returned for the previous arg.
+func (me *Parser) tokenize(arg string, previous []token,
+	state *tokenState,
+) ([]token, error) {
 	helpName := "--" + me.HelpName
-	state := me.initializeTokenState()
-	tokens := make([]token, 0, len(args))
-	for i, arg := range args {
-		if me.isHelp(arg, helpName) {
-			tokens = append(tokens, newHelpToken())
-			continue
+	tokens := make([]token, 0, 1)
+	if level, ok := me.isHelp(arg, helpName); ok {
+		return append(tokens, newHelpToken(level)), nil
+	}
+	if topic := me.helpTopic(arg, helpName); topic != "" {
+		return append(tokens, newTopicHelpToken(topic)), nil
+	}
+	if arg == "-" && wantsDash(previous) { // e.g., -o -
+		return append(tokens, newValueToken(arg)), nil
+	}
+	if arg == "-" { // - e.g., for stdin or stdout
+		return append(tokens, newPositionalsFollowToken(),
+			newValueToken(arg)), nil
+	} else if arg == "--" { // --
+		return append(tokens, newPositionalsFollowToken()), nil
+	}
+	if strings.HasPrefix(arg, "--") { // --option --option=value
+		return me.handleLongOption(arg, tokens, state)
+	} else if strings.HasPrefix(arg, "-") {
+		if _, err := strconv.ParseFloat(arg, 64); err == nil {
+			return append(tokens, newValueToken(arg)), nil // -int | -real
 		}
-		if arg == "-" { // - e.g., for stdin or stdout
-			tokens = append(tokens, newPositionalsFollowToken())
-			tokens = append(tokens, newValueToken(arg))
-			for _, v := range args[i+1:] {
-				tokens = append(tokens, newValueToken(v))
-			}
-			break
-		} else if arg == "--" { // --
-			tokens = append(tokens, newPositionalsFollowToken())
-			for _, v := range args[i+1:] {
-				tokens = append(tokens, newValueToken(v))
-			}
-			break
+		return me.handleShortOption(arg, tokens, state)
+	}
+	return append(tokens, newValueToken(arg)), nil
+}
+
+// Returns true if arg looks like an option (e.g., -x, --xyz, or
+// --xyz=value) but isn't one of the Parser's options (or help).
+func (me *Parser) isUnknownOption(arg string, state *tokenState) bool {
+	if arg == "-" || arg == "--" || !strings.HasPrefix(arg, "-") {
+		return false
+	}
+	helpName := "--" + me.HelpName
+	if _, ok := me.isHelp(arg, helpName); ok ||
+		me.helpTopic(arg, helpName) != "" {
+		return false
+	}
+	if name, ok := strings.CutPrefix(arg, "--"); ok {
+		name, _, _ = strings.Cut(name, "=")
+		_, ok = state.optionForLongName[name]
+		return !ok
+	}
+	if _, err := strconv.ParseFloat(arg, 64); err == nil {
+		return false // -int | -real
+	}
+	text, _, _ := strings.Cut(arg[1:], "=")
+	for _, c := range text { // -a -abc -abcValue
+		option, ok := state.optionForShortName[string(c)]
+		if !ok {
+			return true
 		}
-		if strings.HasPrefix(arg, "--") { // --option --option=value
-			tokens, err = me.handleLongOption(arg, tokens, &state)
-			if err != nil {
-				return tokens, err
-			}
-		} else if strings.HasPrefix(arg, "-") {
-			if _, err := strconv.ParseFloat(arg, 64); err == nil {
-				tokens = append(tokens, newValueToken(arg)) // -int | -real
-			} else {
-				tokens, err = me.handleShortOption(arg, tokens, &state)
-				if err != nil {
-					return tokens, err
-				}
-			}
-		} else {
-			tokens = append(tokens, newValueToken(arg))
+		if _, isFlag := option.(*FlagOption); !isFlag {
+			return false // the rest (if any) is its value
 		}
 	}
-	return tokens, nil
+	return false
+}
+
+// Returns true if the last token is a file or path option that wants a
+// value (in which case "-" is its value).
+func wantsDash(tokens []token) bool {
+	if len(tokens) == 0 || tokens[len(tokens)-1].kind != nameTokenKind {
+		return false
+	}
+	option := tokens[len(tokens)-1].option
+	switch option.(type) {
+	case *InputFileOption, *OutputFileOption, *PathOption:
+		return option.wantsValue()
+	}
+	return false
 }
 
 func (me *Parser) initializeTokenState() tokenState {
@@ -414,11 +955,27 @@ func (me *Parser) initializeTokenState() tokenState {
 	return state
 }
 
-func (me *Parser) isHelp(arg, helpName string) bool {
-	if arg == helpName || (me.useLowerhForHelp && arg == "-h") {
-		return true
+func (me *Parser) isHelp(arg, helpName string) (helpLevel, bool) {
+	switch {
+	case arg == helpName:
+		return fullHelp, true
+	case arg == helpName+"-all":
+		return allHelp, true
+	case me.useLowerhForHelp && arg == "-h":
+		if me.BriefHelp {
+			return briefHelp, true
+		}
+		return fullHelp, true
 	}
-	return false
+	return fullHelp, false
+}
+
+// Returns the NAME if arg is --help=NAME; otherwise returns "".
+func (me *Parser) helpTopic(arg, helpName string) string {
+	if topic, ok := strings.CutPrefix(arg, helpName+"="); ok {
+		return topic
+	}
+	return ""
 }
 
 func (me *Parser) handleLongOption(arg string, tokens []token,
@@ -428,9 +985,14 @@ func (me *Parser) handleLongOption(arg string, tokens []token,
 	left, right, found := strings.Cut(name, "=")
 	if found { // --option=value
 		option, ok := state.optionForLongName[left]
-		if ok {
+		if _, isFlag := option.(*FlagOption); ok && isFlag {
+			return tokens, me.handleError(eUnexpectedValue, fmt.Sprintf(
+				"unexpected value %q for flag --%s", right, left))
+		} else if ok {
 			tokens = append(tokens, newNameToken(left, option))
 			tokens = append(tokens, newValueToken(right))
+		} else if me.CollectUnknown {
+			tokens = append(tokens, newUnknownToken(arg))
 		} else {
 			return tokens, me.handleError(eUnrecognizedOption,
 				"unrecognized option --"+left)
@@ -439,6 +1001,8 @@ func (me *Parser) handleLongOption(arg string, tokens []token,
 		option, ok := state.optionForLongName[name]
 		if ok {
 			tokens = append(tokens, newNameToken(name, option))
+		} else if me.CollectUnknown {
+			tokens = append(tokens, newUnknownToken(arg))
 		} else {
 			return tokens, me.handleError(eUnrecognizedOption,
 				"unrecognized option --"+name)
@@ -478,170 +1042,108 @@ func (me *Parser) handleShortOption(arg string, tokens []token,
 					"unexpected value "+rest)
 			}
 			break
+		} else if me.CollectUnknown { // -x -xValue -x=value
+			unknown := "-" + text[i:]
+			if len(parts) == 2 {
+				unknown += "=" + pendingValue
+				pendingValue = ""
+			}
+			tokens = append(tokens, newUnknownToken(unknown))
+			break
 		} else {
 			return tokens, me.handleError(eUnrecognizedOption,
 				"unrecognized option -"+name)
 		}
 	}
 	if pendingValue != "" {
+		if last := len(tokens) - 1; last >= 0 && isFlag && text != "" {
+			return tokens, me.handleError(eUnexpectedValue, fmt.Sprintf(
+				"unexpected value %q for flag -%s", pendingValue,
+				tokens[last].text))
+		}
 		tokens = append(tokens, newValueToken(pendingValue))
 	}
 	return tokens, nil
 }
 
-// OnHelp shows the help text and quits.
-func (me *Parser) OnHelp() {
-	me.dropHidden()
-	text := ""
-	if me.ShortDesc != "" {
-		text += uterm.Wrapped(me.ShortDesc, me.width) + "\n\n"
+func (me *Parser) helpText(option optioner) string {
+	common := option.common()
+	parts := []string{option.Help()}
+	if me.ShowDefaults && len(common.choices) > 0 && me.ChoicesFormat != "" {
+		parts = append(parts, fmt.Sprintf(me.ChoicesFormat,
+			strings.Join(common.choices, " ")))
 	}
-	text += me.usageLine()
-	text += me.maybeWithDescriptionAndPositionals()
-	text += me.optionsHelp()
-	if me.EndDesc != "" {
-		text += "\n" + uterm.Wrapped(me.EndDesc, me.width) + "\n"
+	if me.ShowDefaults && common.minimum != "" && me.RangeFormat != "" {
+		parts = append(parts, fmt.Sprintf(me.RangeFormat, common.minimum,
+			common.maximum))
 	}
-	text = strings.TrimSuffix(text, "\n")
-	exitFunc(0, text)
-}
-
-func (me *Parser) dropHidden() {
-	options := make([]optioner, 0, len(me.options))
-	for _, option := range me.options {
-		if !option.isHidden() {
-			options = append(options, option)
+	if common.showDefault == alwaysShowDefault || (me.ShowDefaults &&
+		common.showDefault == parserShowsDefault) {
+		if text := defaultOf(option); text != "" && me.DefaultFormat != "" {
+			parts = append(parts, me.style(me.Theme.Default,
+				fmt.Sprintf(me.DefaultFormat, text)))
 		}
 	}
-	me.options = options
+	return strings.TrimSpace(strings.Join(parts, " "))
 }
 
-func (me *Parser) usageLine() string {
-	text := Emph("usage:") + " " + Strong(me.appName) + " [OPTIONS]"
-	if me.PositionalCount != ZeroPositionals {
-		text = text + " " + positionalCountText(me.PositionalCount,
-			me.positionalVarName1, me.positionalVarNameN)
-	}
-	return text + "\n"
-}
-
-func (me *Parser) maybeWithDescriptionAndPositionals() string {
-	text := ""
-	if me.LongDesc != "" {
-		text = uterm.Wrapped(me.LongDesc, me.width) + "\n"
-	}
-	if me.PositionalCount != ZeroPositionals {
-		posCountText := positionalCountText(me.PositionalCount,
-			me.positionalVarName1, me.positionalVarNameN)
-		text += "\n" + Emph("positional arguments:") + "\n" + columnGap +
-			posCountText
-		if me.PositionalHelp != "" {
-			text += columnGap + ArgHelp(
-				utf8.RuneCountInString(posCountText), me.width,
-				me.PositionalHelp)
-		} else {
-			text += "\n"
-		}
-	}
-	if text != "" {
-		return "\n" + text
+// Returns the text of the option's default (or "" if it has none).
+func defaultOf(option optioner) string {
+	text := option.common().defaultText
+	if texter, ok := option.(defaultTexter); ok && text == "" {
+		text = texter.defaultText()
 	}
 	return text
 }
 
-func (me *Parser) optionsHelp() string {
-	maxLeft := 0
-	data := make([]datum, 0, len(me.options))
+// Returns the options in the given group in the order they were created;
+// or the options that aren't in any group if group is nil.
+func (me *Parser) groupOptions(group *OptionGroup) []optioner {
+	options := make([]optioner, 0, len(me.options))
 	for _, option := range me.options {
-		arg, displayArg := initialArgText(option)
-		optArg := optArgText(option)
-		arg += optArg
-		displayArg += optArg
-		lenArg := utf8.RuneCountInString(arg)
-		if lenArg > maxLeft {
-			maxLeft = lenArg
+		if option.optionGroup() == group {
+			options = append(options, option)
 		}
-		data = append(data, datum{
-			arg: displayArg, lenArg: lenArg,
-			help: option.Help(),
-		})
-
 	}
-	help := columnGap + "-h, --" + me.HelpName
-	lenArg := utf8.RuneCountInString(help)
-	if lenArg > maxLeft {
-		maxLeft = lenArg
-	}
-	data = append(data, datum{
-		arg: columnGap + Strong("-h") + ", " +
-			Strong("--"+me.HelpName), lenArg: lenArg,
-		help: "Show help and quit.",
-	})
-	gapWidth := utf8.RuneCountInString(columnGap)
-	text := "\n" + Emph("optional arguments:") + "\n"
-	allFit := prepareOptionsData(maxLeft, gapWidth, me.width, data)
-	text += optionsDataText(allFit, maxLeft, gapWidth, me.width, data)
-	return text
+	return options
 }
 
-func (me *Parser) onVersion() {
-	exitFunc(0, me.appName+" v"+me.appVersion)
+func (me *Parser) onVersion() error {
+	return me.exit(0, me.appName+" v"+me.appVersion)
 }
 
 func (me *Parser) checkPositionals() error {
-	count := len(me.Positionals)
-	ok := true
-	switch me.PositionalCount {
-	case ZeroPositionals:
-		if count > 0 {
-			ok = false
-		}
-	case ZeroOrOnePositionals:
-		if count > 1 {
-			ok = false
-		}
-	case ZeroToTwoPositionals:
-		if count > 2 {
-			ok = false
-		}
-	case ZeroOrMorePositionals: // any count is valid
-	case OnePositional:
-		if count != 1 {
-			ok = false
-		}
-	case OneOrTwoPositionals:
-		if count == 0 || count > 2 {
-			ok = false
-		}
-	case OneToThreePositionals:
-		if count == 0 || count > 3 {
-			ok = false
-		}
-	case OneOrMorePositionals:
-		if count == 0 {
-			ok = false
-		}
-	case TwoPositionals:
-		if count != 2 {
-			ok = false
-		}
-	case TwoOrThreePositionals:
-		if count < 2 || count > 3 {
-			ok = false
-		}
-	case ThreePositionals:
-		if count != 3 {
-			ok = false
-		}
-	case FourPositionals:
-		if count != 4 {
-			ok = false
+	if len(me.positionals) > 0 {
+		if err := me.checkPositionalPaths(); err != nil {
+			return err
 		}
+		return me.checkNamedPositionals()
 	}
-	if !ok {
+	count := len(me.Positionals)
+	minimum, maximum := me.positionalBounds()
+	if count < minimum || (maximum != Unlimited && count > maximum) {
+		text := me.PositionalCount.String()
+		if me.positionalRange {
+			text = countRangeText(minimum, maximum)
+		}
 		return me.handleError(eWrongPositionalCount,
-			fmt.Sprintf("expected %s positional arguments, got %d",
-				me.PositionalCount, count))
+			fmt.Sprintf("expected %s positional arguments, got %d", text,
+				count))
+	}
+	return me.checkPositionalPaths()
+}
+
+func (me *Parser) checkPositionalPaths() error {
+	if me.PositionalKind == NoPathChecks {
+		return nil
+	}
+	for i, positional := range me.Positionals {
+		path, msg := checkPath("positional path", positional,
+			me.PositionalKind)
+		if msg != "" {
+			return me.handleError(eInvalidValue, msg)
+		}
+		me.Positionals[i] = path
 	}
 	return nil
 }
@@ -652,18 +1154,22 @@ func (me *Parser) checkValues() error {
 			return me.handleError(eInvalidValue, msg)
 		}
 	}
+	for _, group := range me.groups {
+		if msg := group.check(me.groupOptions(group)); msg != "" {
+			return me.handleError(eMutuallyExclusive, msg)
+		}
+	}
 	return nil
 }
 
 func (me *Parser) handleError(code int, msg string) error {
-	exitFunc(2, Hint(fmt.Sprintf("error #%d: %s", code, msg)))
-	return nil // never returns
+	return me.exit(2, fmt.Sprintf("error #%d: %s", code, msg))
 }
 
 // OnError is useful for post parsing validation: use it to display an error
 // in clip's style and quit with exit code 2.
 func (me *Parser) OnError(err error) {
-	exitFunc(2, Hint(err.Error()))
+	_ = me.exit(2, err.Error())
 }
 
 // OnMissing is for use with options that—contradictoraly—are required.
@@ -0,0 +1,90 @@
+// Copyright © 2022 Mark Summerfield. All rights reserved.
+// License: Apache-2.0
+
+package clip
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Returns the transformed path and "" or "" and an error message; what is
+// used as the start of error messages, e.g., "positional path".
+func checkPath(what, path string, checks PathCheck) (string, string) {
+	if path == "" {
+		return "", what + " is empty"
+	}
+	if path == "-" {
+		return path, ""
+	}
+	path = transformPath(path, checks)
+	info, err := os.Stat(path)
+	exists := err == nil
+	if !exists && checks&(PathMustExist|PathMustBeFile|PathMustBeDir|
+		PathReadable) != 0 {
+		return "", fmt.Sprintf("%s %q does not exist", what, path)
+	}
+	if checks&PathMustBeFile != 0 && !info.Mode().IsRegular() {
+		return "", fmt.Sprintf("%s %q is not a file", what, path)
+	}
+	if checks&PathMustBeDir != 0 && !info.IsDir() {
+		return "", fmt.Sprintf("%s %q is not a folder", what, path)
+	}
+	if checks&PathParentMustExist != 0 {
+		info, err := os.Stat(filepath.Dir(path))
+		if err != nil || !info.IsDir() {
+			return "", fmt.Sprintf("%s %q's folder does not exist", what,
+				path)
+		}
+	}
+	if checks&PathReadable != 0 && !isReadable(path) {
+		return "", fmt.Sprintf("%s %q is not readable", what, path)
+	}
+	if checks&PathWritable != 0 && !isWritable(path, exists) {
+		return "", fmt.Sprintf("%s %q is not writable", what, path)
+	}
+	return path, ""
+}
+
+func transformPath(path string, checks PathCheck) string {
+	if checks&PathExpand != 0 {
+		path = expandPath(path)
+	}
+	if checks&PathAbsolute != 0 {
+		if abspath, err := filepath.Abs(path); err == nil {
+			path = abspath
+		}
+	}
+	return path
+}
+
+func expandPath(path string) string {
+	path = os.ExpandEnv(path)
+	if path == "~" || strings.HasPrefix(path, "~/") ||
+		(onWindows && strings.HasPrefix(path, `~\`)) {
+		if home, err := os.UserHomeDir(); err == nil {
+			path = home + path[1:]
+		}
+	}
+	return path
+}
+
+func isReadable(path string) bool {
+	file, err := os.Open(path)
+	if err != nil {
+		return false
+	}
+	file.Close()
+	return true
+}
+
+// A nonexistent path is writable if its folder is. Only the permissions
+// are checked: nothing is opened or created.
+func isWritable(path string, exists bool) bool {
+	if !exists {
+		path = filepath.Dir(path)
+	}
+	return canWrite(path)
+}
@@ -0,0 +1,15 @@
+// Copyright © 2022 Mark Summerfield. All rights reserved.
+// License: Apache-2.0
+
+//go:build !unix
+
+package clip
+
+import "os"
+
+// Returns true if the file or folder's permissions allow writing (on
+// Windows, if it isn't read-only).
+func canWrite(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.Mode().Perm()&0o200 != 0
+}
@@ -0,0 +1,13 @@
+// Copyright © 2022 Mark Summerfield. All rights reserved.
+// License: Apache-2.0
+
+//go:build unix
+
+package clip
+
+import "golang.org/x/sys/unix"
+
+// Returns true if the user may write to the file or folder.
+func canWrite(path string) bool {
+	return unix.Access(path, unix.W_OK) == nil
+}
@@ -0,0 +1,286 @@
+// Copyright © 2022 Mark Summerfield. All rights reserved.
+// License: Apache-2.0
+
+package clip
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Named positionals are declared in order using [Parser.Positional],
+// [Parser.PositionalInt], and [Parser.PositionalRest]. If any are declared
+// they determine how many positionals are wanted (so the Parser's
+// PositionalCount is ignored), the usage (e.g., "<SRC> <DST> [PATTERN
+// ...]"), and the positional arguments' help. The Parser's Positionals
+// still holds all the positionals.
+type positionaler interface {
+	Name() string
+	Help() string
+	Given() bool
+	common() *commonPositional
+	isRest() bool
+	usageText() string
+	addValue(string) string
+	reset()
+}
+
+type commonPositional struct {
+	name     string
+	help     string
+	optional bool
+	given    bool
+}
+
+// Name returns the positional's name, e.g., "SRC".
+func (me *commonPositional) Name() string { return me.name }
+
+// Help returns the positional's help text.
+func (me *commonPositional) Help() string { return me.help }
+
+// Given returns true if the positional was given.
+func (me *commonPositional) Given() bool { return me.given }
+
+// MakeOptional makes the positional optional: if it isn't given its value
+// is its default. Only the last positionals may be optional.
+func (me *commonPositional) MakeOptional() { me.optional = true }
+
+func (me *commonPositional) common() *commonPositional { return me }
+
+func (me *commonPositional) isRest() bool { return false }
+
+// Returns, e.g., "<SRC>" or "[SRC]" if optional.
+func (me *commonPositional) usageText() string {
+	if me.optional {
+		return "[" + me.name + "]"
+	}
+	return "<" + me.name + ">"
+}
+
+// StrPositional is a named positional for accepting a string: see
+// [Parser.Positional].
+type StrPositional struct {
+	*commonPositional
+	TheDefault string       // The value if optional and not given.
+	Validator  StrValidator // A validation function (nil accepts any).
+	value      string
+}
+
+// Value returns the given value or if the positional wasn't given, the
+// default value.
+func (me StrPositional) Value() string {
+	if me.given {
+		return me.value
+	}
+	return me.TheDefault
+}
+
+func (me *StrPositional) addValue(value string) string {
+	if me.Validator != nil {
+		var msg string
+		if value, msg = me.Validator(me.name, value); msg != "" {
+			return msg
+		}
+	}
+	me.value = value
+	me.given = true
+	return ""
+}
+
+func (me *StrPositional) reset() {
+	me.given = false
+	me.value = ""
+}
+
+// IntPositional is a named positional for accepting an int: see
+// [Parser.PositionalInt].
+type IntPositional struct {
+	*commonPositional
+	TheDefault int          // The value if optional and not given.
+	Validator  IntValidator // A validation function.
+	value      int
+}
+
+// Value returns the given value or if the positional wasn't given, the
+// default value.
+func (me IntPositional) Value() int {
+	if me.given {
+		return me.value
+	}
+	return me.TheDefault
+}
+
+func (me *IntPositional) addValue(value string) string {
+	i, msg := me.Validator(me.name, value)
+	if msg != "" {
+		return msg
+	}
+	me.value = i
+	me.given = true
+	return ""
+}
+
+func (me *IntPositional) reset() {
+	me.given = false
+	me.value = 0
+}
+
+// RestPositional is a named positional for accepting all the remaining
+// positionals: see [Parser.PositionalRest].
+type RestPositional struct {
+	*commonPositional
+	Validator StrValidator // A validation function (nil accepts any).
+	value     []string
+}
+
+// Value returns the given values (which is empty if none were given).
+func (me RestPositional) Value() []string {
+	return me.value
+}
+
+// MakeRequired makes the positional require at least one value.
+func (me *RestPositional) MakeRequired() { me.optional = false }
+
+func (me *RestPositional) isRest() bool { return true }
+
+func (me *RestPositional) addValue(value string) string {
+	if me.Validator != nil {
+		var msg string
+		if value, msg = me.Validator(me.name, value); msg != "" {
+			return msg
+		}
+	}
+	me.value = append(me.value, value)
+	me.given = true
+	return ""
+}
+
+func (me *RestPositional) reset() {
+	me.given = false
+	me.value = nil
+}
+
+// Returns, e.g., "[PATTERN ...]" or "<PATTERN> [PATTERN ...]" if required.
+func (me *RestPositional) usageText() string {
+	if me.optional {
+		return "[" + me.name + " ...]"
+	}
+	return "<" + me.name + "> [" + me.name + " ...]"
+}
+
+// Positional creates and returns a new [StrPositional] with the given name
+// (e.g., "SRC") and help text. Positionals are expected in the order they
+// are created.
+func (me *Parser) Positional(name, help string) *StrPositional {
+	positional := &StrPositional{commonPositional: &commonPositional{
+		name: name, help: help}}
+	me.registerNewPositional(positional)
+	return positional
+}
+
+// PositionalInt creates and returns a new [IntPositional] with the given
+// name (e.g., "COUNT"), help text, and default (used if it is made
+// optional and isn't given). Positionals are expected in the order they
+// are created.
+func (me *Parser) PositionalInt(name, help string,
+	theDefault int,
+) *IntPositional {
+	positional := &IntPositional{commonPositional: &commonPositional{
+		name: name, help: help}, TheDefault: theDefault,
+		Validator: makePositionalIntValidator()}
+	me.registerNewPositional(positional)
+	return positional
+}
+
+// PositionalRest creates and returns a new [RestPositional] with the given
+// name (e.g., "PATTERN") and help text that accepts zero or more values
+// (or one or more: see [RestPositional.MakeRequired]). It must be the last
+// positional created.
+func (me *Parser) PositionalRest(name, help string) *RestPositional {
+	positional := &RestPositional{commonPositional: &commonPositional{
+		name: name, help: help, optional: true}}
+	me.registerNewPositional(positional)
+	return positional
+}
+
+func (me *Parser) registerNewPositional(positional positionaler) {
+	err := checkName(positional.Name(), "positional")
+	if err == nil && len(me.positionals) > 0 &&
+		me.positionals[len(me.positionals)-1].isRest() {
+		err = fmt.Errorf("#%d: positional %s can't follow positional %s",
+			eInvalidPositional, positional.Name(),
+			me.positionals[len(me.positionals)-1].Name())
+	}
+	me.positionals = append(me.positionals, positional)
+	if err != nil && me.firstDelayedError == "" {
+		me.firstDelayedError = err.Error()
+	}
+}
+
+func makePositionalIntValidator() func(string, string) (int, string) {
+	return func(name, value string) (int, string) {
+		i, err := strconv.Atoi(value)
+		if err != nil {
+			return 0, fmt.Sprintf("positional %s's value of %q isn't an int",
+				name, value)
+		}
+		return i, ""
+	}
+}
+
+// Returns, e.g., "<SRC> <DST> [PATTERN ...]".
+func (me *Parser) namedPositionalsText() string {
+	texts := make([]string, 0, len(me.positionals))
+	for _, positional := range me.positionals {
+		texts = append(texts, positional.usageText())
+	}
+	return strings.Join(texts, " ")
+}
+
+// Returns "" or an error message if a required named positional follows an
+// optional one.
+func (me *Parser) checkPositionalOrder() string {
+	for i := 1; i < len(me.positionals); i++ {
+		if me.positionals[i-1].common().optional &&
+			!me.positionals[i].common().optional {
+			return fmt.Sprintf("#%d: required positional %s can't follow "+
+				"optional positional %s", eInvalidPositional,
+				me.positionals[i].Name(), me.positionals[i-1].Name())
+		}
+	}
+	return ""
+}
+
+// Gives each named positional its value(s) from the Parser's Positionals.
+func (me *Parser) checkNamedPositionals() error {
+	index := 0
+	for _, positional := range me.positionals {
+		if index == len(me.Positionals) {
+			if !positional.common().optional {
+				return me.handleError(eWrongPositionalCount,
+					"missing positional argument "+positional.Name())
+			}
+			continue
+		}
+		if positional.isRest() {
+			for ; index < len(me.Positionals); index++ {
+				if msg := positional.addValue(
+					me.Positionals[index]); msg != "" {
+					return me.handleError(eInvalidValue, msg)
+				}
+			}
+		} else {
+			if msg := positional.addValue(me.Positionals[index]); msg != "" {
+				return me.handleError(eInvalidValue, msg)
+			}
+			index++
+		}
+	}
+	if index < len(me.Positionals) {
+		return me.handleError(eWrongPositionalCount,
+			fmt.Sprintf("expected at most %d positional arguments, got %d",
+				index, len(me.Positionals)))
+	}
+	return nil
+}
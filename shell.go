@@ -0,0 +1,329 @@
+// Copyright © 2022 Mark Summerfield. All rights reserved.
+// License: Apache-2.0
+
+package clip
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// Shell is an interactive command interpreter, e.g., for an admin console.
+// Each line it reads is a command name followed by that command's
+// arguments, which are parsed by the command's [Parser] before the
+// command is run. Errors are shown in clip's style but don't end the
+// shell. The built-in commands are help (or help NAME), history, and exit
+// (or quit).
+//
+//	shell := clip.NewShell()
+//	add := shell.Command("add", "Add an item.", func(parser *clip.Parser) error {
+//		... // use parser.Positionals and the add options
+//		return nil
+//	})
+//	add.PositionalCount = clip.OnePositional
+//	err := shell.Run()
+type Shell struct {
+	Prompt string    // Default "> "; shown before reading each line.
+	In     io.Reader // Default os.Stdin.
+	Out    io.Writer // Default os.Stdout.
+	Err    io.Writer // Default os.Stderr.
+
+	// The styles and when to use them for the shell's help and error
+	// messages; these are also given to each command's [Parser] when it
+	// is run. The defaults are [DefaultTheme] and ColorAuto.
+	Theme Theme
+	Color ColorMode
+
+	commands []*shellCommand
+	history  []string
+}
+
+type shellCommand struct {
+	name   string
+	help   string
+	parser *Parser
+	run    func(*Parser) error
+}
+
+var shellBuiltins = []string{"exit", "help", "history", "quit"}
+
+// NewShell creates a new shell that reads from stdin.
+func NewShell() Shell {
+	return Shell{Prompt: "> ", In: os.Stdin, Out: os.Stdout,
+		Err: os.Stderr, Theme: DefaultTheme()}
+}
+
+// Command adds a command with the given name and help text and returns its
+// [Parser] (to which options can be added). When the command is given, its
+// arguments are parsed and if they're valid, run is called with the
+// Parser.
+func (me *Shell) Command(name, help string,
+	run func(parser *Parser) error,
+) *Parser {
+	parser := NewParserUser(name, "")
+	parser.Exit = func(int) {} // the shell keeps going
+	command := &shellCommand{name: name, help: help, parser: &parser,
+		run: run}
+	me.commands = append(me.commands, command)
+	return command.parser
+}
+
+// History returns the lines that have been given (excluding empty ones).
+func (me *Shell) History() []string {
+	return me.history
+}
+
+// Run reads and executes lines until exit or quit is given or there's no
+// more input. Returns nil or an error if reading failed.
+func (me *Shell) Run() error {
+	scanner := bufio.NewScanner(me.In)
+	for {
+		if me.Prompt != "" {
+			fmt.Fprint(me.Out, me.Prompt)
+		}
+		if !scanner.Scan() {
+			return scanner.Err()
+		}
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		me.history = append(me.history, line)
+		if !me.Execute(line) {
+			return nil
+		}
+	}
+}
+
+// Execute executes the given line (as if it had been read by
+// [Shell.Run]). Returns false if the line was exit or quit; otherwise
+// true.
+func (me *Shell) Execute(line string) bool {
+	args, err := splitLine(line)
+	if err != nil {
+		me.onError(err.Error())
+		return true
+	}
+	if len(args) == 0 {
+		return true
+	}
+	name, args := args[0], args[1:]
+	switch name {
+	case "exit", "quit":
+		return false
+	case "help":
+		me.onHelp(args)
+		return true
+	case "history":
+		for i, line := range me.history {
+			fmt.Fprintf(me.Out, "%3d  %s\n", i+1, line)
+		}
+		return true
+	}
+	command := me.command(name)
+	if command == nil {
+		me.onError("unknown command " + name + " (for help run: help)")
+		return true
+	}
+	me.prepare(command.parser)
+	if err := command.parser.ParseArgs(args); err != nil {
+		var exitErr *ExitError
+		if !errors.As(err, &exitErr) { // else already shown
+			me.onError(err.Error())
+		}
+		return true
+	}
+	if command.run != nil {
+		if err := command.run(command.parser); err != nil {
+			me.onError(err.Error())
+		}
+	}
+	return true
+}
+
+func (me *Shell) command(name string) *shellCommand {
+	i := slices.IndexFunc(me.commands, func(command *shellCommand) bool {
+		return command.name == name
+	})
+	if i == -1 {
+		return nil
+	}
+	return me.commands[i]
+}
+
+// Makes the parser write to the shell's outputs using the shell's theme
+// and color mode.
+func (me *Shell) prepare(parser *Parser) {
+	parser.Stdout = me.Out
+	parser.Stderr = me.Err
+	parser.Theme = me.Theme
+	parser.Color = me.Color
+}
+
+func (me *Shell) onHelp(args []string) {
+	if len(args) > 0 {
+		if command := me.command(args[0]); command != nil {
+			parser := command.parser
+			me.prepare(parser)
+			if err := parser.prepareHelpAndVersionOptions(); err != nil {
+				return // already shown
+			}
+			if len(args) > 1 {
+				parser.OnTopicHelp(args[1])
+			} else {
+				parser.OnHelp()
+			}
+		} else {
+			me.onError("unknown command " + args[0])
+		}
+		return
+	}
+	data := make([]datum, 0, len(me.commands)+len(shellBuiltins))
+	maxLeft := 0
+	add := func(name, help string) {
+		arg := columnGap + name
+		lenArg := utf8.RuneCountInString(arg)
+		maxLeft = max(maxLeft, lenArg)
+		data = append(data, datum{arg: columnGap +
+			me.style(me.Theme.OptionName, name),
+			lenArg: lenArg, help: help})
+	}
+	for _, command := range me.commands {
+		add(command.name, command.help)
+	}
+	add("help", "Show the commands or a command's help (help NAME).")
+	add("history", "Show the lines given so far.")
+	add("exit", "Quit (or use quit).")
+	width := GetWidth()
+	gapWidth := utf8.RuneCountInString(columnGap)
+	allFit := prepareOptionsData(maxLeft, gapWidth, width, data)
+	fmt.Fprint(me.Out, me.style(me.Theme.Heading, "commands:")+"\n"+
+		optionsDataText(allFit, maxLeft, gapWidth, width, data))
+}
+
+func (me *Shell) onError(msg string) {
+	if styleWanted(me.Color, writerStyled(me.Err, errTTY)) {
+		msg = styled(me.Theme.Error, msg)
+	}
+	fmt.Fprintln(me.Err, msg)
+}
+
+func (me *Shell) style(sgr, s string) string {
+	if styleWanted(me.Color, writerStyled(me.Out, tty)) {
+		return styled(sgr, s)
+	}
+	return s
+}
+
+// Complete returns the possible completions for the last word of the
+// given line (which is "" if the line ends with a space), e.g., for a
+// line-editing library's tab completion. Commands, option names, and
+// choices (e.g., for [Parser.Choice] options) are completed.
+func (me *Shell) Complete(line string) []string {
+	words := strings.Fields(line)
+	if line == "" || strings.HasSuffix(line, " ") {
+		words = append(words, "")
+	}
+	prefix := words[len(words)-1]
+	if len(words) == 1 || (len(words) == 2 && words[0] == "help") {
+		names := slices.Clone(shellBuiltins)
+		for _, command := range me.commands {
+			names = append(names, command.name)
+		}
+		slices.Sort(names)
+		return withPrefix(names, prefix)
+	}
+	command := me.command(words[0])
+	if command == nil {
+		return nil
+	}
+	parser := command.parser
+	if name, value, found := strings.Cut(prefix, "="); found &&
+		strings.HasPrefix(name, "--") {
+		if option := parser.optionForTopic(name); option != nil {
+			choices := withPrefix(option.common().choices, value)
+			for i, choice := range choices {
+				choices[i] = name + "=" + choice
+			}
+			return choices
+		}
+		return nil
+	}
+	if strings.HasPrefix(prefix, "-") {
+		names := make([]string, 0, len(parser.options)+1)
+		for _, option := range parser.options {
+			if !option.isHidden() {
+				names = append(names, "--"+option.LongName())
+			}
+		}
+		names = append(names, "--"+parser.HelpName)
+		return withPrefix(names, prefix)
+	}
+	if previous := words[len(words)-2]; strings.HasPrefix(previous, "-") {
+		if option := parser.optionForTopic(previous); option != nil {
+			return withPrefix(option.common().choices, prefix)
+		}
+	}
+	return nil
+}
+
+func withPrefix(words []string, prefix string) []string {
+	matches := make([]string, 0, len(words))
+	for _, word := range words {
+		if strings.HasPrefix(word, prefix) {
+			matches = append(matches, word)
+		}
+	}
+	return matches
+}
+
+// Splits the line into words, respecting single and double quotes and
+// backslash escapes (e.g., add "two words" or add two\ words).
+func splitLine(line string) ([]string, error) {
+	var words []string
+	var word strings.Builder
+	inWord := false
+	var quote rune
+	escaped := false
+	for _, c := range line {
+		switch {
+		case escaped:
+			word.WriteRune(c)
+			escaped = false
+		case c == '\\' && quote != '\'':
+			escaped = true
+			inWord = true
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			} else {
+				word.WriteRune(c)
+			}
+		case c == '"' || c == '\'':
+			quote = c
+			inWord = true
+		case c == ' ' || c == '\t':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(c)
+			inWord = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote", quote)
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
@@ -0,0 +1,95 @@
+// Copyright © 2022 Mark Summerfield. All rights reserved.
+// License: Apache-2.0
+
+package clip
+
+import (
+	"fmt"
+	"strings"
+)
+
+type subcommand struct {
+	name   string
+	help   string
+	parser *Parser
+}
+
+// Subcommand adds a subcommand with the given name (e.g., "add") and help
+// text and returns its [Parser] (to which options, positionals, and
+// further subcommands can be added). A Parser that has subcommands
+// requires its first positional to be one of their names: the arguments
+// before it are the Parser's own options and the arguments after it are
+// parsed by that subcommand's Parser. (So, e.g., "myapp -v add -f x"
+// gives -v to myapp and -f and x to add.) See also [Parser.Command].
+//
+// The subcommand's Parser starts with this Parser's Theme, and when it
+// parses, is given this Parser's Stdout, Stderr, Exit, and Color (or
+// --color=MODE if given).
+func (me *Parser) Subcommand(name, help string) *Parser {
+	parser := NewParserUser(me.appName+" "+name, "")
+	parser.Theme = me.Theme
+	if err := checkName(name, "subcommand"); err != nil &&
+		me.firstDelayedError == "" {
+		me.firstDelayedError = err.Error()
+	}
+	me.subcommands = append(me.subcommands, &subcommand{name: name,
+		help: help, parser: &parser})
+	return &parser
+}
+
+// Command returns the name of the subcommand that was given (after
+// parsing), or "" if the Parser has no subcommands. See
+// [Parser.Subcommand].
+func (me *Parser) Command() string {
+	return me.command
+}
+
+// Called once the command (which is the first positional) has been read
+// and args holds the arguments that follow it. Completes this Parser's
+// parse and then parses the args using the command's Parser.
+func (me *Parser) parseSubcommand(args []string) error {
+	if len(me.Positionals) == 0 {
+		return me.handleError(eInvalidCommand,
+			"expected a command: "+me.commandNames())
+	}
+	name := me.Positionals[0]
+	var command *subcommand
+	for _, sub := range me.subcommands {
+		if sub.name == name {
+			command = sub
+			break
+		}
+	}
+	if command == nil {
+		return me.handleError(eInvalidCommand, fmt.Sprintf(
+			"unknown command %q: expected %s", name, me.commandNames()))
+	}
+	me.Positionals = nil
+	if err := me.finishParse(); err != nil {
+		return err
+	}
+	me.command = name
+	parser := command.parser
+	parser.Stdout, parser.Stderr, parser.Exit = me.Stdout, me.Stderr, me.Exit
+	parser.Color = me.Color
+	if me.colorGiven {
+		parser.Color = me.givenColor
+	}
+	return parser.ParseArgs(args)
+}
+
+// Returns, e.g., "add, list, or remove".
+func (me *Parser) commandNames() string {
+	names := make([]string, 0, len(me.subcommands))
+	for _, sub := range me.subcommands {
+		names = append(names, sub.name)
+	}
+	switch len(names) {
+	case 1:
+		return names[0]
+	case 2:
+		return names[0] + " or " + names[1]
+	}
+	return strings.Join(names[:len(names)-1], ", ") + ", or " +
+		names[len(names)-1]
+}
@@ -0,0 +1,143 @@
+// Copyright © 2022 Mark Summerfield. All rights reserved.
+// License: Apache-2.0
+
+package clip
+
+import (
+	"encoding"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// TextOption is an option for accepting a single value of any type that
+// implements [encoding.TextUnmarshaler]. The given value is unmarshaled
+// directly into the value the option was created with.
+type TextOption struct {
+	*commonOption
+	AllowImplicit bool // If true, giving the option with no value means use the default.
+	value         encoding.TextUnmarshaler
+	theDefault    string
+	initial       reflect.Value // A copy of *value when created (if valid).
+}
+
+// Always returns a *TextOption; _and_ either nil or error.
+func newTextOption(name, help string,
+	ptr encoding.TextUnmarshaler,
+) (*TextOption, error) {
+	err := checkName(name, "option")
+	shortName, longName := namesForName(name)
+	option := &TextOption{commonOption: &commonOption{longName: longName,
+		shortName: shortName, help: help, state: notGiven,
+		showDefault: alwaysShowDefault}, value: ptr,
+		theDefault: marshaledText(ptr)}
+	if value := reflect.ValueOf(ptr); value.Kind() == reflect.Pointer &&
+		!value.IsNil() {
+		option.initial = reflect.New(value.Elem().Type()).Elem()
+		option.initial.Set(value.Elem())
+	}
+	return option, err
+}
+
+// Value returns the value the option was created with: this holds the
+// given value or if the option wasn't given, the default value.
+func (me TextOption) Value() encoding.TextUnmarshaler {
+	return me.value
+}
+
+func (me TextOption) wantsValue() bool {
+	return me.state == given
+}
+
+func (me TextOption) check() string {
+	if me.state == given && !me.AllowImplicit {
+		return "expected exactly one value for " + me.LongName() +
+			", got none"
+	}
+	return ""
+}
+
+func (me *TextOption) addValue(value string) string {
+	if err := me.value.UnmarshalText([]byte(value)); err != nil {
+		return invalidVarValue(me.longName, value, err)
+	}
+	me.state = hadValue
+	return ""
+}
+
+// Restores the default if a value was given: by unmarshaling its text (so
+// that values which own memory, e.g., big.Int, don't share it) or if the
+// type has no text (or it doesn't unmarshal), by copying it.
+func (me *TextOption) reset() {
+	if me.state == hadValue {
+		if me.theDefault == "" ||
+			me.value.UnmarshalText([]byte(me.theDefault)) != nil {
+			if me.initial.IsValid() {
+				reflect.ValueOf(me.value).Elem().Set(me.initial)
+			}
+		}
+	}
+	me.commonOption.reset()
+}
+
+func (me TextOption) argText() string {
+	if me.AllowImplicit {
+		return " [" + me.VarName() + "]"
+	}
+	return " " + me.VarName()
+}
+
+func (me TextOption) defaultText() string {
+	return me.theDefault
+}
+
+// TextUnmarshalerPtr is satisfied by any *T which implements
+// [encoding.TextUnmarshaler]; see [Texts].
+type TextUnmarshalerPtr[T any] interface {
+	*T
+	encoding.TextUnmarshaler
+}
+
+// Texts creates and returns a new [VarsOption], --name or -n (where n is
+// the first rune in name), help is the option's help text, and ptr points
+// to a slice which holds the default values (if any) and which is replaced
+// with the given values (if the option is given). Each value is parsed
+// using its type's UnmarshalText method. By default this option accepts
+// [OneOrMoreValues] (see [ValueCount]). (Go doesn't support generic
+// methods so this is a function rather than a [Parser] method.)
+//
+//	parser := NewParser()
+//	addrs := []netip.Addr{netip.MustParseAddr("127.0.0.1")}
+//	Texts(&parser, "addr", "The addresses to listen on", &addrs)
+//	parser.ParseLine("-a 192.168.1.7 192.168.1.8") // addrs has 2 values
+func Texts[T any, PT TextUnmarshalerPtr[T]](parser *Parser, name,
+	help string, ptr *[]T,
+) *VarsOption[T] {
+	defaults := make([]string, 0, len(*ptr))
+	for i := range *ptr {
+		if text := marshaledText(PT(&(*ptr)[i])); text != "" {
+			defaults = append(defaults, text)
+		}
+	}
+	option := Vars(parser, name, help, func(value string) (T, error) {
+		var v T
+		err := PT(&v).UnmarshalText([]byte(value))
+		return v, err
+	})
+	option.bound = ptr
+	option.boundInit = slices.Clone(*ptr)
+	option.theDefault = strings.Join(defaults, " ")
+	option.ShowDefault()
+	return option
+}
+
+// Returns the value's text if it implements encoding.TextMarshaler (and
+// doesn't fail); otherwise returns "".
+func marshaledText(value any) string {
+	if marshaler, ok := value.(encoding.TextMarshaler); ok {
+		if text, err := marshaler.MarshalText(); err == nil {
+			return string(text)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,158 @@
+// Copyright © 2022 Mark Summerfield. All rights reserved.
+// License: Apache-2.0
+
+package clip
+
+import (
+	"io"
+	"os"
+	"strings"
+)
+
+// ANSI SGR escape sequences.
+const (
+	sgrReset     = "\x1B[0m"
+	sgrBold      = "\x1B[1m"
+	sgrItalic    = "\x1B[3m"
+	sgrUnderline = "\x1B[4m"
+	sgrRed       = "\x1B[91m"
+)
+
+// ColorMode says when the help and error messages are styled.
+// See [Parser.Color].
+type ColorMode uint8
+
+const (
+	ColorAuto   ColorMode = iota // Style if the output is a terminal (see [Parser.Color]).
+	ColorAlways                  // Always style.
+	ColorNever                   // Never style.
+)
+
+var colorModeNames = []string{"auto", "always", "never"}
+
+// Theme holds the ANSI SGR escape sequences (e.g., "\x1B[1m" for bold)
+// used to style the help and error messages. A "" style means unstyled.
+// See [DefaultTheme] and [Parser.Theme].
+type Theme struct {
+	Heading    string // e.g., "usage:" and "optional arguments:"
+	OptionName string // e.g., "-v" and "--verbose" (and the app's name)
+	VarName    string // e.g., "FILE" in "--outfile FILE"
+	Error      string // Error messages.
+	Hint       string // The "for help run: ..." hint after an error.
+	Default    string // e.g., "[default: 2]" (see [Parser.ShowDefaults])
+}
+
+// DefaultTheme returns the theme that parsers use by default: bold option
+// names, italic headings (underlined on windows), red underlined errors
+// (red italic on windows), and red hints.
+func DefaultTheme() Theme {
+	if onWindows {
+		return Theme{Heading: sgrUnderline, OptionName: sgrBold,
+			Error: sgrRed + sgrItalic, Hint: sgrRed}
+	}
+	return Theme{Heading: sgrItalic, OptionName: sgrBold,
+		Error: sgrRed + sgrUnderline, Hint: sgrRed}
+}
+
+// Returns true if output to a stream that is (or isn't) a terminal should
+// be styled, as the environment allows: NO_COLOR (if not "") means never
+// style, FORCE_COLOR (if not "" or "0") means always style, and TERM=dumb
+// means never style.
+func colorWanted(terminal bool) bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	if force := os.Getenv("FORCE_COLOR"); force != "" && force != "0" {
+		return true
+	}
+	if os.Getenv("TERM") == "dumb" {
+		return false
+	}
+	return terminal
+}
+
+func isTerminal(file *os.File) bool {
+	info, err := file.Stat()
+	return err == nil && (info.Mode()&os.ModeCharDevice) != 0
+}
+
+func styled(sgr, s string) string {
+	if sgr == "" || s == "" {
+		return s
+	}
+	return sgr + s + sgrReset
+}
+
+// Returns s styled with the given SGR sequence if stdout should be styled.
+func (me *Parser) style(sgr, s string) string {
+	if me.colorFor(writerStyled(me.Stdout, tty)) {
+		return styled(sgr, s)
+	}
+	return s
+}
+
+// Returns s styled with the given SGR sequence if stderr should be styled.
+func (me *Parser) styleError(sgr, s string) string {
+	if me.colorFor(writerStyled(me.Stderr, errTTY)) {
+		return styled(sgr, s)
+	}
+	return s
+}
+
+// Returns true if output to the writer should be styled (or the
+// fallback if the writer is nil, i.e., stdout or stderr).
+func writerStyled(writer io.Writer, fallback bool) bool {
+	if writer == nil {
+		return fallback
+	}
+	file, ok := writer.(*os.File)
+	return colorWanted(ok && isTerminal(file))
+}
+
+func (me *Parser) colorFor(auto bool) bool {
+	mode := me.Color
+	if me.colorGiven {
+		mode = me.givenColor
+	}
+	return styleWanted(mode, auto)
+}
+
+// Returns true if output should be styled given the color mode and
+// whether it should be for ColorAuto.
+func styleWanted(mode ColorMode, auto bool) bool {
+	switch mode {
+	case ColorAlways:
+		return true
+	case ColorNever:
+		return false
+	}
+	return auto
+}
+
+// If the Parser has a ColorName, overrides its Color with the last
+// --color=MODE or --color MODE in the args (providing MODE is valid). This
+// is done before parsing so that any help or error message is styled
+// accordingly.
+func (me *Parser) colorFromArgs(args []string) {
+	if me.ColorName == "" {
+		return
+	}
+	name := "--" + me.ColorName
+	for i, arg := range args {
+		if arg == "--" {
+			break
+		}
+		mode, ok := strings.CutPrefix(arg, name+"=")
+		if !ok && arg == name && i+1 < len(args) {
+			mode, ok = args[i+1], true
+		}
+		if ok {
+			for j, modeName := range colorModeNames {
+				if mode == modeName {
+					me.givenColor = ColorMode(j)
+					me.colorGiven = true
+				}
+			}
+		}
+	}
+}
@@ -17,12 +17,14 @@ const (
 	valueTokenKind
 	positionalsFollowTokenKind
 	helpTokenKind
+	unknownTokenKind // An unrecognized option (see Parser.CollectUnknown).
 )
 
 type token struct {
 	text   string
 	option optioner
 	kind   tokenKind
+	level  helpLevel // Only used for help tokens.
 }
 
 func (me token) String() string {
@@ -51,6 +53,14 @@ func newPositionalsFollowToken() token {
 	return token{kind: positionalsFollowTokenKind}
 }
 
-func newHelpToken() token {
-	return token{kind: helpTokenKind}
+func newUnknownToken(text string) token {
+	return token{text: text, kind: unknownTokenKind}
+}
+
+func newHelpToken(level helpLevel) token {
+	return token{kind: helpTokenKind, level: level}
+}
+
+func newTopicHelpToken(topic string) token {
+	return token{text: topic, kind: helpTokenKind}
 }
@@ -0,0 +1,149 @@
+// Copyright © 2022 Mark Summerfield. All rights reserved.
+// License: Apache-2.0
+
+package clip
+
+import (
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/mark-summerfield/uterm"
+)
+
+const topicsName = "topics"
+
+type helpTopic struct {
+	name string
+	text string
+}
+
+// HelpTopic adds a help topic with the given name and text: this is shown
+// when --help=NAME is given. (If the name is the same as an option's long
+// name, the option's help is shown instead.) Giving --help=topics lists
+// the topics.
+//
+//	parser.HelpTopic("formats", "The supported formats are...")
+func (me *Parser) HelpTopic(name, text string) {
+	me.topics = append(me.topics, helpTopic{name, text})
+}
+
+// Shows the help for the given option, topic, or the list of topics, and
+// quits.
+func (me *Parser) onTopicHelp(name string) error {
+	text := ""
+	if option := me.optionForTopic(name); option != nil {
+		text = me.optionTopicText(option)
+	} else if name == topicsName {
+		text = me.topicsText()
+	} else if i := slices.IndexFunc(me.topics, func(topic helpTopic) bool {
+		return topic.name == name
+	}); i > -1 {
+		text = uterm.Wrapped(me.topics[i].text, me.width)
+	} else {
+		return me.handleError(eUnknownHelpTopic, "unknown help topic "+name)
+	}
+	return me.showHelp(strings.TrimSuffix(text, "\n"))
+}
+
+// Returns the option with the given long (or short) name, or nil.
+func (me *Parser) optionForTopic(name string) optioner {
+	name = strings.TrimLeft(name, "-")
+	for _, option := range me.options {
+		if option.LongName() == name || (utf8.RuneCountInString(name) == 1 &&
+			option.ShortName() == []rune(name)[0]) {
+			return option
+		}
+	}
+	return nil
+}
+
+func (me *Parser) optionTopicText(option optioner) string {
+	text := me.style(me.Theme.OptionName, "--"+option.LongName())
+	if option.ShortName() != NoShortName {
+		text = me.style(me.Theme.OptionName,
+			"-"+string(option.ShortName())) + ", " + text
+	}
+	text += me.argTextStyled(optArgText(option)) + "\n\n"
+	help := option.LongHelp()
+	if help == "" {
+		help = option.Help()
+	}
+	if help != "" {
+		text += uterm.Wrapped(help, me.width) + "\n"
+	}
+	details := ""
+	common := option.common()
+	if len(common.choices) > 0 {
+		details += me.style(me.Theme.Heading, "choices:") + " " +
+			strings.Join(common.choices, " ") + "\n"
+	}
+	if common.minimum != "" {
+		details += me.style(me.Theme.Heading, "range:") + " " +
+			common.minimum + ".." + common.maximum + "\n"
+	}
+	if theDefault := defaultOf(option); theDefault != "" &&
+		common.showDefault != neverShowDefault {
+		details += me.style(me.Theme.Heading, "default:") + " " +
+			me.style(me.Theme.Default, theDefault) + "\n"
+	}
+	if details != "" {
+		text += "\n" + details
+	}
+	examples := ""
+	for _, example := range me.examples {
+		if me.usesOption(example.cmdline, option) {
+			examples += me.exampleText(ExampleHelp{
+				CmdLine:     me.appName + " " + example.cmdline,
+				Explanation: example.explanation}, me.width)
+		}
+	}
+	if examples != "" {
+		text += "\n" + me.style(me.Theme.Heading, "examples:") + "\n" +
+			examples
+	}
+	return text
+}
+
+// Returns true if the cmdline has the given option, e.g., -f, --format,
+// --format=VALUE, or in a cluster of short options, e.g., -vf or -vfjson.
+func (me *Parser) usesOption(cmdline string, option optioner) bool {
+	longName := "--" + option.LongName()
+	_, optionForShortName := me.optionsForNames()
+	for _, arg := range strings.Fields(cmdline) {
+		if arg == longName || strings.HasPrefix(arg, longName+"=") {
+			return true
+		}
+		if option.ShortName() == NoShortName ||
+			strings.HasPrefix(arg, "--") || !strings.HasPrefix(arg, "-") {
+			continue
+		}
+		text, _, _ := strings.Cut(arg[1:], "=")
+		for _, c := range text { // -a -abc -abcValue
+			if c == option.ShortName() {
+				return true
+			}
+			other, ok := optionForShortName[string(c)]
+			if !ok {
+				break
+			}
+			if _, isFlag := other.(*FlagOption); !isFlag {
+				break // the rest (if any) is its value
+			}
+		}
+	}
+	return false
+}
+
+func (me *Parser) topicsText() string {
+	text := ""
+	if len(me.topics) > 0 {
+		text = me.style(me.Theme.Heading, "help topics:") + "\n"
+		for _, topic := range me.topics {
+			text += columnGap + topic.name + "\n"
+		}
+		text += "\n"
+	}
+	return text + "for help on an option or topic run: " + me.appName +
+		" --" + me.HelpName + "=NAME\n"
+}
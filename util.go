@@ -5,6 +5,7 @@ package clip
 
 import (
 	"fmt"
+	"math"
 	"os"
 	"runtime"
 	"slices"
@@ -17,14 +18,16 @@ import (
 )
 
 var (
-	tty       bool
-	onWindows bool
+	tty            bool // True if stdout should be styled.
+	errTTY         bool // True if stderr should be styled.
+	stdoutTerminal bool
+	onWindows      bool
 )
 
 func init() {
-	if info, _ := os.Stdout.Stat(); (info.Mode() & os.ModeCharDevice) != 0 {
-		tty = true
-	}
+	stdoutTerminal = isTerminal(os.Stdout)
+	tty = colorWanted(stdoutTerminal)
+	errTTY = colorWanted(isTerminal(os.Stderr))
 	onWindows = runtime.GOOS == "windows"
 }
 
@@ -68,6 +71,73 @@ func makeIntRangeValidator(minimum, maximum int) func(string, string) (int,
 	}
 }
 
+func makeIntRangesValidator(minimum, maximum int) func(string,
+	string) ([]IntRange, string) {
+	return func(name, value string) ([]IntRange, string) {
+		ranges := make([]IntRange, 0, 1)
+		for _, part := range strings.Split(value, ",") {
+			intRange, msg := parseIntRange(name, strings.TrimSpace(part),
+				minimum, maximum)
+			if msg != "" {
+				return nil, msg
+			}
+			ranges = append(ranges, intRange)
+		}
+		return ranges, ""
+	}
+}
+
+// Accepts N, N-M, N- (N to maximum), and -M (minimum to M).
+func parseIntRange(name, text string, minimum, maximum int) (IntRange,
+	string) {
+	var err error
+	intRange := IntRange{Start: minimum, End: maximum}
+	left, right, isRange := strings.Cut(text, "-")
+	if !isRange {
+		if intRange.Start, err = strconv.Atoi(text); err != nil {
+			return IntRange{}, fmt.Sprintf(
+				"option %s's value of %q isn't an int or int range", name,
+				text)
+		}
+		intRange.End = intRange.Start
+	} else {
+		if left == "" && right == "" {
+			return IntRange{}, fmt.Sprintf(
+				"option %s's value of %q isn't an int range", name, text)
+		}
+		if left != "" {
+			if intRange.Start, err = strconv.Atoi(left); err != nil {
+				return IntRange{}, fmt.Sprintf(
+					"option %s's value of %q isn't an int range", name,
+					text)
+			}
+		}
+		if right != "" {
+			if intRange.End, err = strconv.Atoi(right); err != nil {
+				return IntRange{}, fmt.Sprintf(
+					"option %s's value of %q isn't an int range", name,
+					text)
+			}
+		} else if maximum == math.MaxInt {
+			return IntRange{}, fmt.Sprintf(
+				"option %s's open range %q needs a maximum", name, text)
+		}
+	}
+	if intRange.Start < minimum {
+		return IntRange{}, fmt.Sprintf("option %s's minimum is %d, got %d",
+			name, minimum, intRange.Start)
+	}
+	if intRange.End > maximum {
+		return IntRange{}, fmt.Sprintf("option %s's maximum is %d, got %d",
+			name, maximum, intRange.End)
+	}
+	if intRange.Start > intRange.End {
+		return IntRange{}, fmt.Sprintf(
+			"option %s's range %q starts after it ends", name, text)
+	}
+	return intRange, ""
+}
+
 func makeDefaultRealValidator() func(string, string) (float64, string) {
 	return func(name, value string) (float64, string) {
 		r, err := strconv.ParseFloat(value, 64)
@@ -99,6 +169,10 @@ func makeRealRangeValidator(minimum, maximum float64) func(string,
 	}
 }
 
+func realText(r float64) string {
+	return strconv.FormatFloat(r, 'g', -1, 64)
+}
+
 func makeDefaultStrValidator() func(string, string) (string, string) {
 	return func(name, value string) (string, string) {
 		if value == "" {
@@ -125,7 +199,10 @@ func makeChoiceValidator(choices []string) func(string, string) (string,
 	}
 }
 
-func positionalCountText(count PositionalCount, varName1,
+// Returns, e.g., "<FILE1> [FILE2 [FILE3 ...]]" for 1..Unlimited. If
+// there's no maximum the usage shows at most one more optional positional
+// followed by ....
+func positionalRangeText(minimum, maximum int, varName1,
 	varNameN string) string {
 	n := 1
 	if varNameN == "" {
@@ -133,53 +210,74 @@ func positionalCountText(count PositionalCount, varName1,
 		varName1 += "1"
 		n = 2
 	}
-	switch count {
-	case ZeroPositionals:
-		return ""
-	case ZeroOrOnePositionals:
-		return "[" + varName1 + "]"
-	case ZeroToTwoPositionals:
-		return fmt.Sprintf("[%s [%s%d]]", varName1, varNameN, n)
-	case ZeroOrMorePositionals: // any count is valid
-		return fmt.Sprintf("[%s [%s%d ...]]", varName1, varNameN, n)
-	case OnePositional:
-		return "<" + varName1 + ">"
-	case OneOrTwoPositionals:
-		return fmt.Sprintf("<%s> [%s%d]", varName1, varNameN, n)
-	case OneToThreePositionals:
-		return fmt.Sprintf("<%s> [%s%d [%s%d]]", varName1, varNameN, n,
-			varNameN, n+1)
-	case OneOrMorePositionals:
-		return fmt.Sprintf("<%s> [%s%d [%s%d ...]]", varName1, varNameN, n,
-			varNameN, n+1)
-	case TwoPositionals:
-		return fmt.Sprintf("<%s> <%s%d>", varName1, varNameN, n)
-	case TwoOrThreePositionals:
-		return fmt.Sprintf("<%s> <%s%d> [%s%d]", varName1, varNameN, n,
-			varNameN, n+1)
-	case ThreePositionals:
-		return fmt.Sprintf("<%s> <%s%d> <%s%d>", varName1, varNameN, n,
-			varNameN, n+1)
-	case FourPositionals:
-		return fmt.Sprintf("<%s> <%s%d> <%s%d> <%s%d>", varName1, varNameN,
-			n, varNameN, n+1, varNameN, n+2)
+	name := func(i int) string {
+		if i == 0 {
+			return varName1
+		}
+		return fmt.Sprintf("%s%d", varNameN, n+i-1)
+	}
+	texts := make([]string, 0, minimum+1)
+	for i := 0; i < minimum; i++ {
+		texts = append(texts, "<"+name(i)+">")
+	}
+	last := maximum
+	if maximum == Unlimited {
+		last = minimum + 1
+	}
+	optional := ""
+	for i := last - 1; i >= minimum; i-- {
+		if i == last-1 && maximum == Unlimited {
+			optional = "[" + name(i) + " [" + name(i+1) + " ...]]"
+		} else if optional == "" {
+			optional = "[" + name(i) + "]"
+		} else {
+			optional = "[" + name(i) + " " + optional + "]"
+		}
+	}
+	if optional != "" {
+		texts = append(texts, optional)
 	}
-	panic("BUG: missing PositionalCount case")
+	return strings.Join(texts, " ")
 }
 
-func valueCountText(count ValueCount, varName string) string {
-	switch count {
-	case OneOrMoreValues:
-		return fmt.Sprintf("<%s1> [%s2 ...]", varName, varName)
-	case TwoValues:
-		return fmt.Sprintf("<%s1> <%s2>", varName, varName)
-	case ThreeValues:
-		return fmt.Sprintf("<%s1> <%s2> <%s3>", varName, varName, varName)
-	case FourValues:
-		return fmt.Sprintf("<%s1> <%s2> <%s3> <%s4>", varName, varName,
-			varName, varName)
+// Returns, e.g., "exactly 2", "at least 3", "at most 2", or "2 to 6".
+func countRangeText(minimum, maximum int) string {
+	switch {
+	case minimum == maximum:
+		return fmt.Sprintf("exactly %d", minimum)
+	case maximum == Unlimited:
+		return fmt.Sprintf("at least %d", minimum)
+	case minimum == 0:
+		return fmt.Sprintf("at most %d", maximum)
 	}
-	panic("BUG: missing ValueCount case")
+	return fmt.Sprintf("%d to %d", minimum, maximum)
+}
+
+// Returns, e.g., "<V1> [V2 ...]" for 1..Unlimited or "<V1> <V2> [V3 [V4]]"
+// for 2..4.
+func valueCountText(count ValueCount, minValues, maxValues int,
+	varName string) string {
+	minimum, maximum := valueBounds(count, minValues, maxValues)
+	texts := make([]string, 0, minimum+1)
+	for i := 1; i <= minimum; i++ {
+		texts = append(texts, fmt.Sprintf("<%s%d>", varName, i))
+	}
+	if maximum == Unlimited {
+		texts = append(texts, fmt.Sprintf("[%s%d ...]", varName, minimum+1))
+	} else {
+		optional := ""
+		for i := maximum; i > minimum; i-- {
+			if optional == "" {
+				optional = fmt.Sprintf("[%s%d]", varName, i)
+			} else {
+				optional = fmt.Sprintf("[%s%d %s]", varName, i, optional)
+			}
+		}
+		if optional != "" {
+			texts = append(texts, optional)
+		}
+	}
+	return strings.Join(texts, " ")
 }
 
 // ArgHelp is used internally by clip, but made public because it can be
@@ -214,21 +312,6 @@ func GetWidth() int {
 	return 80
 }
 
-func initialArgText(option optioner) (string, string) {
-	arg := "--" + option.LongName()
-	displayArg := Strong(arg)
-	if option.ShortName() != NoShortName {
-		arg = fmt.Sprintf("%s-%c, %s", columnGap, option.ShortName(),
-			arg)
-		displayArg = columnGap + Strong("-"+string(option.ShortName())) +
-			", " + displayArg
-	} else {
-		arg = fmt.Sprintf("%s    %s", columnGap, arg)
-		displayArg = columnGap + "    " + displayArg
-	}
-	return arg, displayArg
-}
-
 func optArgText(option optioner) string {
 	switch opt := option.(type) {
 	case *IntOption:
@@ -243,18 +326,43 @@ func optArgText(option optioner) string {
 		} else {
 			return " " + opt.VarName()
 		}
+	case *PathOption:
+		if opt.AllowImplicit {
+			return " [" + opt.VarName() + "]"
+		} else {
+			return " " + opt.VarName()
+		}
+	case *InputFileOption:
+		if opt.AllowImplicit {
+			return " [" + opt.VarName() + "]"
+		} else {
+			return " " + opt.VarName()
+		}
+	case *OutputFileOption:
+		if opt.AllowImplicit {
+			return " [" + opt.VarName() + "]"
+		} else {
+			return " " + opt.VarName()
+		}
 	case *StrOption:
 		if opt.AllowImplicit {
 			return " [" + opt.VarName() + "]"
 		} else {
 			return " " + opt.VarName()
 		}
+	case *IntRangesOption:
+		return " " + opt.VarName()
 	case *IntsOption:
-		return " " + valueCountText(opt.ValueCount, opt.VarName())
+		return " " + valueCountText(opt.ValueCount, opt.MinValues,
+			opt.MaxValues, opt.VarName())
 	case *RealsOption:
-		return " " + valueCountText(opt.ValueCount, opt.VarName())
+		return " " + valueCountText(opt.ValueCount, opt.MinValues,
+			opt.MaxValues, opt.VarName())
 	case *StrsOption:
-		return " " + valueCountText(opt.ValueCount, opt.VarName())
+		return " " + valueCountText(opt.ValueCount, opt.MinValues,
+			opt.MaxValues, opt.VarName())
+	case argTexter: // e.g., VarOption and VarsOption
+		return opt.argText()
 	}
 	return ""
 }
@@ -286,17 +394,19 @@ func optionsDataText(allFit bool, maxLeft, gapWidth, width int,
 				}
 				text.WriteString(columnGap + ArgHelp(maxLeft, width, datum.help))
 			}
+		} else {
+			text.WriteString("\n")
 		}
 	}
 	return text.String()
 }
 
 // Strong returns the given string contained within terminal escape codes to
-// make it bold on linux and bold or colored on windows (providing os.Stdout
-// is a TTY).
+// make it bold on linux and bold or colored on windows (providing stdout
+// should be styled: see [ColorMode]).
 func Strong(s string) string {
 	if tty {
-		return uterm.Bold(s)
+		return styled(sgrBold, s)
 	}
 	return s
 }
@@ -309,28 +419,28 @@ func Bold(s string) string {
 }
 
 // Empth returns the given string contained within terminal escape codes to
-// make it italic on linux and underlined on windows (providing os.Stdout is
-// a TTY).
+// make it italic on linux and underlined on windows (providing stdout
+// should be styled: see [ColorMode]).
 func Emph(s string) string {
 	if tty {
 		if onWindows {
-			return uterm.Underline(s)
+			return styled(sgrUnderline, s)
 		}
-		return uterm.Italic(s)
+		return styled(sgrItalic, s)
 	}
 	return s
 }
 
 // Hint returns the given string contained within terminal escape codes to
 // make it underlined on linux and italic on windows (although I've never
-// known italics to actually work on windows) (providing os.Stdout is a
-// TTY).
+// known italics to actually work on windows) (providing stdout should be
+// styled: see [ColorMode]).
 func Hint(s string) string {
 	if tty {
 		if onWindows {
-			return uterm.Italic(s)
+			return styled(sgrItalic, s)
 		}
-		return uterm.Underline(s)
+		return styled(sgrUnderline, s)
 	}
 	return s
 }
@@ -0,0 +1,188 @@
+// Copyright © 2022 Mark Summerfield. All rights reserved.
+// License: Apache-2.0
+
+package clip
+
+import (
+	"fmt"
+	"reflect"
+	"slices"
+)
+
+// VarOption is an option for accepting a single value of any type T; the
+// value is parsed (and validated) by the option's Parse function.
+type VarOption[T any] struct {
+	*commonOption
+	TheDefault    T                       // The options default value.
+	AllowImplicit bool                    // If true, giving the option with no value means use the default.
+	Parse         func(string) (T, error) // A parsing and validation function.
+	value         T
+}
+
+// Var creates and returns a new [VarOption], --name or -n (where n is the
+// first rune in name), help is the option's help text, theDefault is the
+// option's default, and parse is used to convert the given string into a
+// valid T or return an error. (Go doesn't support generic methods so this
+// is a function rather than a [Parser] method.)
+//
+//	parser := NewParser()
+//	addrOpt := Var(&parser, "addr", "The address to listen on",
+//		netip.MustParseAddr("127.0.0.1"), netip.ParseAddr)
+//	parser.ParseLine("-a 192.168.1.7")
+//	addr := addrOpt.Value() // addr is a netip.Addr
+func Var[T any](parser *Parser, name, help string, theDefault T,
+	parse func(string) (T, error),
+) *VarOption[T] {
+	err := checkName(name, "option")
+	shortName, longName := namesForName(name)
+	option := &VarOption[T]{commonOption: &commonOption{longName: longName,
+		shortName: shortName, help: help, state: notGiven},
+		TheDefault: theDefault, Parse: parse}
+	parser.registerNewOption(option, err)
+	return option
+}
+
+// Value returns the given value or if the option wasn't given, the default
+// value.
+func (me VarOption[T]) Value() T {
+	if me.state == hadValue {
+		return me.value
+	}
+	return me.TheDefault
+}
+
+func (me VarOption[T]) wantsValue() bool {
+	return me.state == given
+}
+
+func (me VarOption[T]) check() string {
+	if me.state == given && !me.AllowImplicit {
+		return "expected exactly one value for " + me.LongName() +
+			", got none"
+	}
+	return ""
+}
+
+func (me *VarOption[T]) addValue(value string) string {
+	v, err := me.Parse(value)
+	if err != nil {
+		return invalidVarValue(me.longName, value, err)
+	}
+	me.value = v
+	me.state = hadValue
+	return ""
+}
+
+func (me VarOption[T]) defaultText() string {
+	if text := marshaledText(me.TheDefault); text != "" {
+		return text
+	}
+	if reflect.ValueOf(&me.TheDefault).Elem().IsZero() {
+		return ""
+	}
+	return fmt.Sprint(me.TheDefault)
+}
+
+func (me VarOption[T]) valueText() string {
+	return fmt.Sprint(me.Value())
+}
+
+func (me VarOption[T]) argText() string {
+	if me.AllowImplicit {
+		return " [" + me.VarName() + "]"
+	}
+	return " " + me.VarName()
+}
+
+// VarsOption is an option for accepting one or more values of any type T;
+// each value is parsed (and validated) by the option's Parse function.
+type VarsOption[T any] struct {
+	*commonOption
+	ValueCount ValueCount              // How many values are wanted.
+	MinValues  int                     // If not 0, overrides the ValueCount's minimum.
+	MaxValues  int                     // If not 0, overrides its maximum (or Unlimited).
+	Parse      func(string) (T, error) // A parsing and validation function.
+	value      []T
+	bound      *[]T   // If not nil, set to value when value is set.
+	boundInit  []T    // The bound slice's initial (default) values.
+	theDefault string // The default's text for the help.
+}
+
+// Vars creates and returns a new [VarsOption], --name or -n (where n is
+// the first rune in name), help is the option's help text, and parse is
+// used to convert each given string into a valid T or return an error. By
+// default this option accepts [OneOrMoreValues] (see [ValueCount]). (Go
+// doesn't support generic methods so this is a function rather than a
+// [Parser] method.)
+func Vars[T any](parser *Parser, name, help string,
+	parse func(string) (T, error),
+) *VarsOption[T] {
+	err := checkName(name, "option")
+	shortName, longName := namesForName(name)
+	option := &VarsOption[T]{commonOption: &commonOption{
+		longName: longName, shortName: shortName, help: help,
+		state: notGiven}, ValueCount: OneOrMoreValues, Parse: parse}
+	parser.registerNewOption(option, err)
+	return option
+}
+
+// Value returns the given value(s) or nil.
+func (me VarsOption[T]) Value() []T {
+	return me.value
+}
+
+func (me VarsOption[T]) wantsValue() bool {
+	return me.state != notGiven
+}
+
+func (me VarsOption[T]) check() string {
+	return checkMulti(me.LongName(), me.state, me.ValueCount, me.MinValues,
+		me.MaxValues, len(me.value))
+}
+
+func (me VarsOption[T]) checkValueCount() string {
+	return checkValueBounds(me.LongName(), me.ValueCount, me.MinValues,
+		me.MaxValues)
+}
+
+func (me *VarsOption[T]) addValue(value string) string {
+	v, err := me.Parse(value)
+	if err != nil {
+		return invalidVarValue(me.longName, value, err)
+	}
+	if me.value == nil {
+		me.value = make([]T, 0, 1)
+	}
+	me.value = append(me.value, v)
+	me.state = hadValue
+	if me.bound != nil {
+		*me.bound = me.value
+	}
+	return ""
+}
+
+func (me *VarsOption[T]) reset() {
+	me.commonOption.reset()
+	me.value = nil
+	if me.bound != nil {
+		*me.bound = slices.Clone(me.boundInit)
+	}
+}
+
+func (me VarsOption[T]) valueText() string {
+	return fmt.Sprint(me.Value())
+}
+
+func (me VarsOption[T]) argText() string {
+	return " " + valueCountText(me.ValueCount, me.MinValues, me.MaxValues,
+		me.VarName())
+}
+
+func (me VarsOption[T]) defaultText() string {
+	return me.theDefault
+}
+
+func invalidVarValue(name, value string, err error) string {
+	return fmt.Sprintf("option %s's value of %q is invalid: %s", name, value,
+		err)
+}